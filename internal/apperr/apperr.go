@@ -0,0 +1,81 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code tells clients what kind of error happened, it is sent next to error message
+type Code string
+
+const (
+	// Validation means request is malformed or has wrong values
+	Validation Code = "validation"
+	// NotFound means requested resource does not exist
+	NotFound Code = "not_found"
+	// Unauthorized means user could not be authenticated
+	Unauthorized Code = "unauthorized"
+	// Forbidden means user is known but has no access to resource
+	Forbidden Code = "forbidden"
+	// Conflict means resource already exists or was changed concurrently
+	Conflict Code = "conflict"
+	// UpstreamUnavailable means db or auth did not answer
+	UpstreamUnavailable Code = "upstream_unavailable"
+	// Internal is any error that was not classified
+	Internal Code = "internal"
+)
+
+// Error is error with code, message is safe to show to clients
+type Error struct {
+	Code    Code
+	Message string
+	err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+func New(code Code, message string) error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap gives err a code, wrapped err is still matched by errors.Is and errors.As
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: err.Error(), err: err}
+}
+
+// CodeOf returns code of first Error in chain of err, errors without code are Internal
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return Internal
+}
+
+// Status returns http status code is answered with
+func Status(code Code) int {
+	switch code {
+	case Validation:
+		return http.StatusBadRequest
+	case NotFound:
+		return http.StatusNotFound
+	case Unauthorized:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case Conflict:
+		return http.StatusConflict
+	case UpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
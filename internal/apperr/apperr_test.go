@@ -0,0 +1,69 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	base := errors.New("mongo: no documents in result")
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"new", New(Forbidden, "no access"), Forbidden},
+		{"wrapped", Wrap(NotFound, base), NotFound},
+		{"wrapped by fmt", fmt.Errorf("get song: %w", Wrap(Validation, base)), Validation},
+		// outer code wins
+		{"wrapped twice", Wrap(Conflict, Wrap(NotFound, base)), Conflict},
+		{"plain", base, Internal},
+		{"nil", nil, Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	base := errors.New("connection refused")
+	err := Wrap(UpstreamUnavailable, base)
+	if !errors.Is(err, base) {
+		t.Error("wrapped error is not matched by errors.Is")
+	}
+	if err.Error() != base.Error() {
+		t.Errorf("message = %q, want %q", err.Error(), base.Error())
+	}
+	if Wrap(Internal, nil) != nil {
+		t.Error("Wrap(nil) is not nil")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		code Code
+		want int
+	}{
+		{Validation, http.StatusBadRequest},
+		{NotFound, http.StatusNotFound},
+		{Unauthorized, http.StatusUnauthorized},
+		{Forbidden, http.StatusForbidden},
+		{Conflict, http.StatusConflict},
+		{UpstreamUnavailable, http.StatusServiceUnavailable},
+		{Internal, http.StatusInternalServerError},
+		{"unknown", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			if got := Status(tt.code); got != tt.want {
+				t.Errorf("Status() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package artwork
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	// registered for image.Decode
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Sizes are edge lengths in pixels every cover is stored in
+var Sizes = []int{64, 300, 640}
+
+const jpegQuality = 85
+
+// MaxPixels bounds size of decoded image, small file can decode to gigabytes of pixels
+const MaxPixels = 5000 * 5000
+
+var (
+	ErrUnsupportedFormat = errors.New("image format is not supported")
+	ErrTooLarge          = fmt.Errorf("image has more than %d pixels", MaxPixels)
+)
+
+// ID is content hash of original image, so the same cover is stored once
+// and served images never change under the same name
+func ID(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:10])
+}
+
+// FileName is name resized cover is stored and served by
+func FileName(id string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", id, size)
+}
+
+// Resize crops image to square around its center and scales it to every size,
+// results are jpeg encoded. Images smaller than size are scaled up.
+// Dimensions are checked against MaxPixels before image is decoded.
+func Resize(data []byte, sizes []int) (map[int][]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	edge := bounds.Dx()
+	if bounds.Dy() < edge {
+		edge = bounds.Dy()
+	}
+	if edge == 0 {
+		return nil, errors.New("image is empty")
+	}
+	x := bounds.Min.X + (bounds.Dx()-edge)/2
+	y := bounds.Min.Y + (bounds.Dy()-edge)/2
+	square := image.Rect(x, y, x+edge, y+edge)
+
+	result := make(map[int][]byte, len(sizes))
+	for _, size := range sizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Src, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		result[size] = buf.Bytes()
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package artwork
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// tag builds ID3v2 tag of version with frames, data after tag is kept out
+func tag(version, flags byte, body ...[]byte) []byte {
+	joined := bytes.Join(body, nil)
+	header := []byte{'I', 'D', '3', version, 0, flags}
+	return append(append(header, syncsafeBytes(len(joined))...), joined...)
+}
+
+func frame(version byte, id string, flags byte, body []byte) []byte {
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(body)))
+	if version == 4 {
+		size = syncsafeBytes(len(body))
+	}
+	header := append([]byte(id), size...)
+	return append(append(header, 0, flags), body...)
+}
+
+func apic(encoding, pictureType byte, description string, picture []byte) []byte {
+	body := append([]byte{encoding}, "image/jpeg\x00"...)
+	body = append(body, pictureType)
+	body = append(body, description...)
+	if encoding == 1 || encoding == 2 {
+		body = append(body, 0, 0)
+	} else {
+		body = append(body, 0)
+	}
+	return append(body, picture...)
+}
+
+// unsync applies unsynchronisation scheme to the whole tag as ID3v2.3 writers do
+func unsync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff}, []byte{0xff, 0x00})
+}
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+func TestExtract(t *testing.T) {
+	front := []byte("front cover")
+	back := []byte("back cover")
+	title := frame(3, "TIT2", 0, []byte("\x00Yesterday"))
+
+	tests := []struct {
+		name   string
+		data   []byte
+		want   []byte
+		wantOK bool
+	}{
+		{"no tag", []byte("\xff\xfb\x90\x00 mp3 frame"), nil, false},
+		{"too short", []byte("ID3"), nil, false},
+		{"unsupported version", tag(2, 0, frame(3, "APIC", 0, apic(0, 3, "", front))), nil, false},
+		{"no picture", tag(3, 0, title), nil, false},
+		{"tag longer than file", tag(3, 0, frame(3, "APIC", 0, apic(0, 3, "", front)))[:20], nil, false},
+		{"v3 picture", tag(3, 0, title, frame(3, "APIC", 0, apic(0, 0, "cover", back))), back, true},
+		{
+			name: "front cover is preferred",
+			data: tag(3, 0,
+				frame(3, "APIC", 0, apic(0, 4, "back", back)),
+				frame(3, "APIC", 0, apic(0, 3, "front", front)),
+			),
+			want:   front,
+			wantOK: true,
+		},
+		{
+			name:   "v4 utf-16 description",
+			data:   tag(4, 0, frame(4, "APIC", 0, apic(1, 3, "\xff\xfec\x00", front))),
+			want:   front,
+			wantOK: true,
+		},
+		{
+			name:   "padding after frames",
+			data:   tag(3, 0, frame(3, "APIC", 0, apic(3, 3, "", front)), make([]byte, 32)),
+			want:   front,
+			wantOK: true,
+		},
+		{
+			name:   "v3 extended header",
+			data:   tag(3, 0x40, []byte{0, 0, 0, 6, 0, 0, 0, 0, 0, 0}, frame(3, "APIC", 0, apic(0, 3, "", front))),
+			want:   front,
+			wantOK: true,
+		},
+		{
+			name:   "v4 extended header",
+			data:   tag(4, 0x40, []byte{0, 0, 0, 6, 1, 0}, frame(4, "APIC", 0, apic(0, 3, "", front))),
+			want:   front,
+			wantOK: true,
+		},
+		{
+			name:   "v3 unsynchronised tag",
+			data:   tag(3, 0x80, unsync(frame(3, "APIC", 0, apic(0, 3, "", []byte{0xff, 0xd8, 0xff, 0xe0})))),
+			want:   []byte{0xff, 0xd8, 0xff, 0xe0},
+			wantOK: true,
+		},
+		{
+			name:   "v4 unsynchronised frame",
+			data:   tag(4, 0, frame(4, "APIC", 0x02, apic(0, 3, "", []byte{0xff, 0x00, 0xd8}))),
+			want:   []byte{0xff, 0xd8},
+			wantOK: true,
+		},
+		{
+			// data length indicator holds size of frame data before unsynchronisation
+			name:   "v4 frame with data length indicator",
+			data:   tag(4, 0, frame(4, "APIC", 0x03, append(syncsafeBytes(16), apic(0, 3, "", []byte{0xff, 0x00, 0xd8})...))),
+			want:   []byte{0xff, 0xd8},
+			wantOK: true,
+		},
+		{"data length indicator without data", tag(4, 0, frame(4, "APIC", 0x01, []byte{0, 0})), nil, false},
+		{"picture without data", tag(3, 0, frame(3, "APIC", 0, apic(0, 3, "", nil))), nil, false},
+		{"mime type not terminated", tag(3, 0, frame(3, "APIC", 0, []byte("\x00image/jpeg"))), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// audio after tag is ignored
+			data := append(append([]byte(nil), tt.data...), "\xff\xfb\x90\x00"...)
+			got, ok := Extract(data)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 20))
+	for x := 0; x < 30; x++ {
+		for y := 0; y < 20; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 8), G: uint8(y * 12), A: 255})
+		}
+	}
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatal(err)
+	}
+	// gif logical screen of 6000x6000 without any frame, only header is read
+	hugeGIF := []byte("GIF89a\x70\x17\x70\x17\x00\x00\x00\x3b")
+
+	tests := []struct {
+		name    string
+		data    []byte
+		sizes   []int
+		wantErr bool
+		// errIs is checked when set
+		errIs error
+	}{
+		{name: "png", data: pngData.Bytes(), sizes: []int{8, 64}},
+		{name: "no sizes", data: pngData.Bytes()},
+		{name: "not an image", data: []byte("not an image"), sizes: []int{8}, wantErr: true, errIs: ErrUnsupportedFormat},
+		{name: "too large", data: hugeGIF, sizes: []int{8}, wantErr: true, errIs: ErrTooLarge},
+		{name: "truncated png", data: pngData.Bytes()[:60], sizes: []int{8}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resize(tt.data, tt.sizes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Fatalf("err = %v, want %v", err, tt.errIs)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.sizes) {
+				t.Fatalf("got %d images, want %d", len(got), len(tt.sizes))
+			}
+			for _, size := range tt.sizes {
+				resized, err := jpeg.Decode(bytes.NewReader(got[size]))
+				if err != nil {
+					t.Fatalf("size %d is not jpeg: %v", size, err)
+				}
+				if bounds := resized.Bounds(); bounds.Dx() != size || bounds.Dy() != size {
+					t.Errorf("size %d has bounds %v", size, bounds)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package artwork
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	id3HeaderLen = 10
+	frontCover   = 3
+)
+
+// Extract returns picture from APIC frame of ID3v2.3 or ID3v2.4 tag in the
+// beginning of mp3 file. Front cover is preferred if tag holds several pictures.
+func Extract(data []byte) ([]byte, bool) {
+	if len(data) < id3HeaderLen || string(data[:3]) != "ID3" {
+		return nil, false
+	}
+	version, flags := data[3], data[5]
+	if version != 3 && version != 4 {
+		return nil, false
+	}
+
+	size := syncsafe(data[6:10])
+	if id3HeaderLen+size > len(data) {
+		return nil, false
+	}
+	tag := data[id3HeaderLen : id3HeaderLen+size]
+	if flags&0x80 != 0 && version == 3 {
+		tag = resync(tag)
+	}
+
+	// skip extended header
+	if flags&0x40 != 0 {
+		if len(tag) < 4 {
+			return nil, false
+		}
+		extSize := int(binary.BigEndian.Uint32(tag[:4])) + 4
+		if version == 4 {
+			extSize = syncsafe(tag[:4])
+		}
+		if extSize > len(tag) {
+			return nil, false
+		}
+		tag = tag[extSize:]
+	}
+
+	var picture []byte
+	for len(tag) >= id3HeaderLen && tag[0] != 0 {
+		id := string(tag[:4])
+		frameSize := int(binary.BigEndian.Uint32(tag[4:8]))
+		if version == 4 {
+			frameSize = syncsafe(tag[4:8])
+		}
+		formatFlags := tag[9]
+		if id3HeaderLen+frameSize > len(tag) {
+			break
+		}
+		frame := tag[id3HeaderLen : id3HeaderLen+frameSize]
+		tag = tag[id3HeaderLen+frameSize:]
+
+		if id != "APIC" {
+			continue
+		}
+		if version == 4 {
+			// data length indicator is prepended to frame data
+			if formatFlags&0x01 != 0 {
+				if len(frame) < 4 {
+					continue
+				}
+				frame = frame[4:]
+			}
+			if formatFlags&0x02 != 0 {
+				frame = resync(frame)
+			}
+		}
+		data, pictureType, ok := parseAPIC(frame)
+		if !ok {
+			continue
+		}
+		if pictureType == frontCover {
+			return data, true
+		}
+		if picture == nil {
+			picture = data
+		}
+	}
+
+	return picture, picture != nil
+}
+
+// parseAPIC returns picture data and type from APIC frame body
+func parseAPIC(frame []byte) ([]byte, byte, bool) {
+	if len(frame) < 2 {
+		return nil, 0, false
+	}
+	encoding := frame[0]
+
+	// mime type is always latin1
+	end := bytes.IndexByte(frame[1:], 0)
+	if end < 0 || end+3 > len(frame) {
+		return nil, 0, false
+	}
+	pictureType := frame[end+2]
+	rest := frame[end+3:]
+
+	// description is terminated with one zero byte or two for utf-16
+	if encoding == 1 || encoding == 2 {
+		i := 0
+		for ; i+1 < len(rest); i += 2 {
+			if rest[i] == 0 && rest[i+1] == 0 {
+				break
+			}
+		}
+		if i+1 >= len(rest) {
+			return nil, 0, false
+		}
+		rest = rest[i+2:]
+	} else {
+		i := bytes.IndexByte(rest, 0)
+		if i < 0 {
+			return nil, 0, false
+		}
+		rest = rest[i+1:]
+	}
+
+	if len(rest) == 0 {
+		return nil, 0, false
+	}
+	return rest, pictureType, true
+}
+
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// resync reverts unsynchronisation scheme, 0xFF 0x00 becomes 0xFF
+func resync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff, 0x00}, []byte{0xff})
+}
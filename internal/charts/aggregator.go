@@ -0,0 +1,191 @@
+package charts
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// user top ranges
+const (
+	RangeShortTerm  = "4_weeks"
+	RangeMediumTerm = "6_months"
+	RangeLongTerm   = "all_time"
+)
+
+// global chart windows
+const (
+	WindowDay   = "day"
+	WindowWeek  = "week"
+	WindowMonth = "month"
+)
+
+// recentLimit is how many recently played songs are kept per user
+const recentLimit = 50
+
+var (
+	userRanges   = []string{RangeShortTerm, RangeMediumTerm, RangeLongTerm}
+	userLengths  = []int{28, 182, 0}
+	chartWindows = []string{WindowDay, WindowWeek, WindowMonth}
+	chartLengths = []int{1, 7, 30}
+
+	ErrUnknownRange = errors.New("unknown range")
+)
+
+// Play is song played by user at time
+type Play struct {
+	SongID   string
+	PlayedAt time.Time
+}
+
+type userStats struct {
+	songs   *windowed
+	artists *windowed
+	// recent is ordered from newest to oldest
+	recent []Play
+}
+
+// Aggregator keeps per user and global play counts in memory
+type Aggregator struct {
+	mu     sync.Mutex
+	global *windowed
+	users  map[string]*userStats
+	now    func() time.Time
+}
+
+func NewAggregator() *Aggregator {
+	a := &Aggregator{
+		users: make(map[string]*userStats),
+		now:   time.Now,
+	}
+	a.global = newWindowed(chartLengths, a.today())
+	return a
+}
+
+func dayOf(t time.Time) int64 {
+	return t.UTC().Unix() / int64(24*time.Hour/time.Second)
+}
+
+func (a *Aggregator) today() int64 {
+	return dayOf(a.now())
+}
+
+// AddPlay counts play of song, plays without user count only in global charts
+func (a *Aggregator) AddPlay(userID, songID, artist string, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	day := dayOf(at)
+	a.global.add(day, songID)
+	if userID == "" {
+		return
+	}
+	stats := a.user(userID)
+	stats.songs.add(day, songID)
+	if artist != "" {
+		stats.artists.add(day, artist)
+	}
+}
+
+// AddRecent puts song to user recently played, the same song played again is moved to the top
+func (a *Aggregator) AddRecent(userID, songID string, at time.Time) {
+	if userID == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := a.user(userID)
+	pos := 0
+	for pos < len(stats.recent) && stats.recent[pos].PlayedAt.After(at) {
+		pos++
+	}
+	// events loaded from db may come out of order
+	if pos > 0 && stats.recent[pos-1].SongID == songID {
+		return
+	}
+	if pos < len(stats.recent) && stats.recent[pos].SongID == songID {
+		stats.recent[pos].PlayedAt = at
+		return
+	}
+	stats.recent = append(stats.recent, Play{})
+	copy(stats.recent[pos+1:], stats.recent[pos:])
+	stats.recent[pos] = Play{SongID: songID, PlayedAt: at}
+	if len(stats.recent) > recentLimit {
+		stats.recent = stats.recent[:recentLimit]
+	}
+}
+
+func (a *Aggregator) RecentlyPlayed(userID string, limit int) []Play {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats, ok := a.users[userID]
+	if !ok {
+		return nil
+	}
+	if limit <= 0 || limit > len(stats.recent) {
+		limit = len(stats.recent)
+	}
+	return append([]Play(nil), stats.recent[:limit]...)
+}
+
+func (a *Aggregator) TopSongs(userID, timeRange string, limit int) ([]Count, error) {
+	return a.userTop(userID, timeRange, limit, func(s *userStats) *windowed { return s.songs })
+}
+
+func (a *Aggregator) TopArtists(userID, timeRange string, limit int) ([]Count, error) {
+	return a.userTop(userID, timeRange, limit, func(s *userStats) *windowed { return s.artists })
+}
+
+func (a *Aggregator) userTop(userID, timeRange string, limit int, counts func(*userStats) *windowed) ([]Count, error) {
+	i := indexOf(userRanges, timeRange)
+	if i < 0 {
+		return nil, ErrUnknownRange
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats, ok := a.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	w := counts(stats)
+	w.advance(a.today())
+	return w.top(i, limit), nil
+}
+
+// Chart returns most played songs of all users over window
+func (a *Aggregator) Chart(window string, limit int) ([]Count, error) {
+	i := indexOf(chartWindows, window)
+	if i < 0 {
+		return nil, ErrUnknownRange
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.global.advance(a.today())
+	return a.global.top(i, limit), nil
+}
+
+func (a *Aggregator) user(userID string) *userStats {
+	stats, ok := a.users[userID]
+	if !ok {
+		today := a.today()
+		stats = &userStats{
+			songs:   newWindowed(userLengths, today),
+			artists: newWindowed(userLengths, today),
+		}
+		a.users[userID] = stats
+	}
+	return stats
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,108 @@
+package charts
+
+import "sort"
+
+// Count is number of plays of song or artist
+type Count struct {
+	Key   string
+	Plays int
+}
+
+// windowed keeps play counts over several sliding windows of days. Totals are
+// updated on every play and when a day leaves a window, so reads never scan plays.
+type windowed struct {
+	// lengths of windows in days, 0 is all time
+	lengths []int
+	// maxLength is the longest finite window, buckets older than it are dropped
+	maxLength int64
+	buckets   map[int64]map[string]int
+	totals    []map[string]int
+	today     int64
+}
+
+func newWindowed(lengths []int, today int64) *windowed {
+	w := &windowed{
+		lengths: lengths,
+		buckets: make(map[int64]map[string]int),
+		totals:  make([]map[string]int, len(lengths)),
+		today:   today,
+	}
+	for i, length := range lengths {
+		w.totals[i] = make(map[string]int)
+		if int64(length) > w.maxLength {
+			w.maxLength = int64(length)
+		}
+	}
+	return w
+}
+
+func (w *windowed) add(day int64, key string) {
+	w.advance(day)
+	for i, length := range w.lengths {
+		if length == 0 || day > w.today-int64(length) {
+			w.totals[i][key]++
+		}
+	}
+
+	if w.maxLength == 0 || day <= w.today-w.maxLength {
+		return
+	}
+	bucket, ok := w.buckets[day]
+	if !ok {
+		bucket = make(map[string]int)
+		w.buckets[day] = bucket
+	}
+	bucket[key]++
+}
+
+// advance moves today forward subtracting days that left every window
+func (w *windowed) advance(day int64) {
+	if day <= w.today {
+		return
+	}
+	// everything finite expired, no need to walk day by day
+	if day-w.today > w.maxLength {
+		w.buckets = make(map[int64]map[string]int)
+		for i, length := range w.lengths {
+			if length != 0 {
+				w.totals[i] = make(map[string]int)
+			}
+		}
+		w.today = day
+		return
+	}
+
+	for w.today < day {
+		w.today++
+		for i, length := range w.lengths {
+			if length == 0 {
+				continue
+			}
+			for key, n := range w.buckets[w.today-int64(length)] {
+				w.totals[i][key] -= n
+				if w.totals[i][key] <= 0 {
+					delete(w.totals[i], key)
+				}
+			}
+		}
+		delete(w.buckets, w.today-w.maxLength)
+	}
+}
+
+// top returns limit most played keys of window i
+func (w *windowed) top(i, limit int) []Count {
+	result := make([]Count, 0, len(w.totals[i]))
+	for key, plays := range w.totals[i] {
+		result = append(result, Count{Key: key, Plays: plays})
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Plays != result[b].Plays {
+			return result[a].Plays > result[b].Plays
+		}
+		return result[a].Key < result[b].Key
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
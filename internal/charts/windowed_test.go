@@ -0,0 +1,163 @@
+package charts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWindowedAdvance(t *testing.T) {
+	type play struct {
+		day int64
+		key string
+	}
+	// windows of 1 and 7 days and all time, today is day 100
+	plays := []play{
+		{100, "a"},
+		{100, "b"},
+		{95, "b"},
+		// older than every finite window, counted only in all time
+		{90, "c"},
+	}
+
+	tests := []struct {
+		name    string
+		advance int64
+		// want per window
+		want [3][]Count
+	}{
+		{
+			name:    "same day",
+			advance: 100,
+			want: [3][]Count{
+				{{"a", 1}, {"b", 1}},
+				{{"b", 2}, {"a", 1}},
+				{{"b", 2}, {"a", 1}, {"c", 1}},
+			},
+		},
+		{
+			name:    "past day is ignored",
+			advance: 99,
+			want: [3][]Count{
+				{{"a", 1}, {"b", 1}},
+				{{"b", 2}, {"a", 1}},
+				{{"b", 2}, {"a", 1}, {"c", 1}},
+			},
+		},
+		{
+			name:    "day leaves shortest window",
+			advance: 101,
+			want: [3][]Count{
+				{},
+				{{"b", 2}, {"a", 1}},
+				{{"b", 2}, {"a", 1}, {"c", 1}},
+			},
+		},
+		{
+			name:    "several days at once",
+			advance: 102,
+			want: [3][]Count{
+				{},
+				{{"a", 1}, {"b", 1}},
+				{{"b", 2}, {"a", 1}, {"c", 1}},
+			},
+		},
+		{
+			name:    "every finite window expired",
+			advance: 107,
+			want: [3][]Count{
+				{},
+				{},
+				{{"b", 2}, {"a", 1}, {"c", 1}},
+			},
+		},
+		{
+			name:    "jump over longest window",
+			advance: 200,
+			want: [3][]Count{
+				{},
+				{},
+				{{"b", 2}, {"a", 1}, {"c", 1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWindowed([]int{1, 7, 0}, 100)
+			for _, p := range plays {
+				w.add(p.day, p.key)
+			}
+			w.advance(tt.advance)
+
+			for i, want := range tt.want {
+				if got := w.top(i, 0); !reflect.DeepEqual(got, want) {
+					t.Errorf("window %d: top = %v, want %v", w.lengths[i], got, want)
+				}
+			}
+			if tt.advance > 107 && len(w.buckets) != 0 {
+				t.Errorf("buckets are kept after every window expired: %v", w.buckets)
+			}
+		})
+	}
+}
+
+func TestWindowedAdd(t *testing.T) {
+	w := newWindowed([]int{1, 7, 0}, 100)
+	// play of later day moves today forward
+	w.add(100, "a")
+	w.add(102, "a")
+	// late play is counted only in windows still covering its day
+	w.add(98, "b")
+
+	want := [3][]Count{
+		{{"a", 1}},
+		{{"a", 2}, {"b", 1}},
+		{{"a", 2}, {"b", 1}},
+	}
+	for i := range want {
+		if got := w.top(i, 0); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("window %d: top = %v, want %v", w.lengths[i], got, want[i])
+		}
+	}
+	if w.today != 102 {
+		t.Errorf("today = %d, want 102", w.today)
+	}
+	if got := w.top(2, 1); !reflect.DeepEqual(got, []Count{{"a", 2}}) {
+		t.Errorf("top with limit = %v", got)
+	}
+}
+
+func TestAggregatorRanges(t *testing.T) {
+	now := time.Now()
+	a := NewAggregator()
+	a.now = func() time.Time { return now }
+	a.AddPlay("u1", "s1", "queen", now)
+	a.AddPlay("u1", "s1", "queen", now.AddDate(0, -2, 0))
+	a.AddPlay("", "s2", "abba", now)
+
+	tests := []struct {
+		name    string
+		top     func() ([]Count, error)
+		want    []Count
+		wantErr error
+	}{
+		{"short term", func() ([]Count, error) { return a.TopSongs("u1", RangeShortTerm, 0) }, []Count{{"s1", 1}}, nil},
+		{"medium term", func() ([]Count, error) { return a.TopSongs("u1", RangeMediumTerm, 0) }, []Count{{"s1", 2}}, nil},
+		{"artists", func() ([]Count, error) { return a.TopArtists("u1", RangeLongTerm, 0) }, []Count{{"queen", 2}}, nil},
+		{"unknown user", func() ([]Count, error) { return a.TopSongs("u2", RangeLongTerm, 0) }, nil, nil},
+		{"unknown range", func() ([]Count, error) { return a.TopSongs("u1", "week", 0) }, nil, ErrUnknownRange},
+		{"chart", func() ([]Count, error) { return a.Chart(WindowDay, 0) }, []Count{{"s1", 1}, {"s2", 1}}, nil},
+		{"unknown window", func() ([]Count, error) { return a.Chart(RangeLongTerm, 0) }, nil, ErrUnknownRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.top()
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Breaker stops calls to downstream after failureThreshold failures in a row.
+// After cooldown one trial call is let through, its result closes or opens breaker again.
+type Breaker struct {
+	mu               sync.Mutex
+	state            string
+	failures         int
+	openedAt         time.Time
+	trialInFlight    bool
+	failureThreshold int
+	cooldown         time.Duration
+	onChange         func(from, to string)
+	now              func() time.Time
+}
+
+func NewBreaker(failureThreshold int, cooldown time.Duration, onChange func(from, to string)) *Breaker {
+	return &Breaker{
+		state:            StateClosed,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		onChange:         onChange,
+		now:              time.Now,
+	}
+}
+
+// Allow returns ErrCircuitOpen if call should fail fast
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(StateHalfOpen)
+		b.trialInFlight = true
+		return nil
+	case StateHalfOpen:
+		if b.trialInFlight {
+			return ErrCircuitOpen
+		}
+		b.trialInFlight = true
+	}
+	return nil
+}
+
+// Done reports result of call let through by Allow
+func (b *Breaker) Done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+	if success {
+		b.failures = 0
+		b.setState(StateClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.failureThreshold {
+		b.openedAt = b.now()
+		b.setState(StateOpen)
+	}
+}
+
+// Cancel releases call let through by Allow without counting its result
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+}
+
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) setState(state string) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(from, state)
+	}
+}
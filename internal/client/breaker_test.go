@@ -0,0 +1,127 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	// step is one call to breaker, wait moves clock forward
+	type step struct {
+		action    string
+		wantErr   error
+		wantState string
+	}
+	allow := func(err error, state string) step { return step{"allow", err, state} }
+	done := func(success bool, state string) step {
+		if success {
+			return step{"success", nil, state}
+		}
+		return step{"failure", nil, state}
+	}
+	cancel := func(state string) step { return step{"cancel", nil, state} }
+	wait := func(state string) step { return step{"wait", nil, state} }
+
+	tests := []struct {
+		name        string
+		steps       []step
+		wantChanges []string
+	}{
+		{
+			name: "failures below threshold",
+			steps: []step{
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateClosed),
+				// success resets failures in a row
+				allow(nil, StateClosed), done(true, StateClosed),
+				allow(nil, StateClosed), done(false, StateClosed),
+			},
+		},
+		{
+			name: "opens after threshold",
+			steps: []step{
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateOpen),
+				allow(ErrCircuitOpen, StateOpen),
+			},
+			wantChanges: []string{"closed>open"},
+		},
+		{
+			name: "successful trial closes",
+			steps: []step{
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateOpen),
+				wait(StateOpen),
+				allow(nil, StateHalfOpen),
+				// only one trial at a time
+				allow(ErrCircuitOpen, StateHalfOpen),
+				done(true, StateClosed),
+				allow(nil, StateClosed),
+			},
+			wantChanges: []string{"closed>open", "open>half-open", "half-open>closed"},
+		},
+		{
+			name: "failed trial opens again",
+			steps: []step{
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateOpen),
+				wait(StateOpen),
+				allow(nil, StateHalfOpen), done(false, StateOpen),
+				// cooldown starts over
+				allow(ErrCircuitOpen, StateOpen),
+				wait(StateOpen),
+				allow(nil, StateHalfOpen),
+			},
+			wantChanges: []string{"closed>open", "open>half-open", "half-open>open", "open>half-open"},
+		},
+		{
+			name: "canceled trial lets next call through",
+			steps: []step{
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateClosed),
+				allow(nil, StateClosed), done(false, StateOpen),
+				wait(StateOpen),
+				allow(nil, StateHalfOpen), cancel(StateHalfOpen),
+				allow(nil, StateHalfOpen), done(true, StateClosed),
+			},
+			wantChanges: []string{"closed>open", "open>half-open", "half-open>closed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes []string
+			b := NewBreaker(3, 10*time.Second, func(from, to string) {
+				changes = append(changes, from+">"+to)
+			})
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			b.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				switch s.action {
+				case "allow":
+					if err := b.Allow(); err != s.wantErr {
+						t.Fatalf("step %d: Allow() = %v, want %v", i, err, s.wantErr)
+					}
+				case "success":
+					b.Done(true)
+				case "failure":
+					b.Done(false)
+				case "cancel":
+					b.Cancel()
+				case "wait":
+					now = now.Add(10 * time.Second)
+				}
+				if state := b.State(); state != s.wantState {
+					t.Fatalf("step %d (%s): state = %s, want %s", i, s.action, state, s.wantState)
+				}
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("state changes = %v, want %v", changes, tt.wantChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/logging"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/metrics"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("github.com/supperdoggy/spotify-web-project/spotify-back/internal/client")
+
+const (
+	breakerFailures = 5
+	breakerCooldown = 30 * time.Second
+	// retryBaseDelay is doubled every retry, real delay is random up to it
+	retryBaseDelay = 100 * time.Millisecond
+)
+
+// Policy is how calls to one endpoint are made, only Idempotent calls are retried
+type Policy struct {
+	Timeout    time.Duration
+	Retries    int
+	Idempotent bool
+}
+
+// DefaultPolicy is used for endpoints without own policy
+var DefaultPolicy = Policy{Timeout: 10 * time.Second}
+
+// Client makes calls to db and auth services with per endpoint timeouts,
+// retries with jitter and circuit breaker per downstream host
+type Client struct {
+	logger   *zap.Logger
+	http     *http.Client
+	policies map[string]Policy
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// New creates client, policies are keyed by url path
+func New(logger *zap.Logger, policies map[string]Policy) *Client {
+	return NewWithTransport(logger, policies, &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+	})
+}
+
+// NewWithTransport creates client sending requests through transport, tests use it to fake downstream
+func NewWithTransport(logger *zap.Logger, policies map[string]Policy, transport http.RoundTripper) *Client {
+	return &Client{
+		logger:   logger,
+		http:     &http.Client{Transport: transport},
+		policies: policies,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// StatusError is returned for 5xx answers of downstream
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s answered with status %d", e.URL, e.Code)
+}
+
+func (c *Client) Post(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, http.MethodPost, url, contentType, data)
+}
+
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodGet, url, "", nil)
+}
+
+// SendRequest sends req as json and unmarshals json answer to resp, nil req is sent without body
+func (c *Client) SendRequest(ctx context.Context, req interface{}, method, url string, resp interface{}) error {
+	var respFromServer *http.Response
+	var data []byte
+	var err error
+
+	if req != nil {
+		data, err = json.Marshal(req)
+		if err != nil {
+			return err
+		}
+	}
+	switch method {
+	case "post":
+		respFromServer, err = c.Do(ctx, http.MethodPost, url, "application/json", data)
+	case "get":
+		respFromServer, err = c.Do(ctx, http.MethodGet, url, "", nil)
+	default:
+		return fmt.Errorf("unknown method %s", method)
+	}
+	if err != nil {
+		return err
+	}
+	defer respFromServer.Body.Close()
+
+	data, err = ioutil.ReadAll(respFromServer.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// Do makes call according to endpoint policy. Body of returned response is
+// already read, so it can be used after timeout of the call. Every attempt
+// is traced and trace context and request id are sent to downstream.
+// Failed calls and open breaker are reported as apperr.UpstreamUnavailable.
+func (c *Client) Do(ctx context.Context, method, rawURL, contentType string, body []byte) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	policy, ok := c.policies[u.Path]
+	if !ok {
+		policy = DefaultPolicy
+	}
+	breaker := c.breaker(u.Host)
+
+	for attempt := 0; ; attempt++ {
+		if err = breaker.Allow(); err != nil {
+			return nil, apperr.Wrap(apperr.UpstreamUnavailable, fmt.Errorf("%s: %w", u.Host, err))
+		}
+
+		started := time.Now()
+		var resp *http.Response
+		resp, err = c.do(ctx, method, rawURL, contentType, body, policy.Timeout)
+		if err != nil && ctx.Err() != nil {
+			// caller is gone, it says nothing about downstream health
+			breaker.Cancel()
+			return nil, err
+		}
+		breaker.Done(err == nil)
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		metrics.DownstreamDuration.WithLabelValues(u.Host, u.Path, result).Observe(time.Since(started).Seconds())
+		if err == nil {
+			return resp, nil
+		}
+
+		if !policy.Idempotent || attempt >= policy.Retries {
+			return nil, apperr.Wrap(apperr.UpstreamUnavailable, err)
+		}
+		delay := time.Duration(rand.Int63n(int64(retryBaseDelay << attempt)))
+		logging.Logger(ctx, c.logger).Warn("retrying request", zap.Error(err), zap.String("url", rawURL), zap.Int("attempt", attempt+1), zap.Duration("delay", delay))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, rawURL, contentType string, body []byte, timeout time.Duration) (resp *http.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	ctx, span := tracer.Start(ctx, method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLFull(rawURL),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, requestID)
+	}
+
+	resp, err = c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, &StatusError{URL: rawURL, Code: resp.StatusCode}
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+func (c *Client) breaker(host string) *Breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.breakers[host]
+	if !ok {
+		b = NewBreaker(breakerFailures, breakerCooldown, func(from, to string) {
+			c.logger.Warn("circuit breaker state changed", zap.String("host", host), zap.String("from", from), zap.String("to", to))
+		})
+		c.breakers[host] = b
+	}
+	return b
+}
+
+// BreakerStates returns state of circuit breaker of every host called so far
+func (c *Client) BreakerStates() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	states := make(map[string]string, len(c.breakers))
+	for host, b := range c.breakers {
+		states[host] = b.State()
+	}
+	return states
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/logging"
+	"go.uber.org/zap"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"request", logging.NewContext(context.Background(), "req-1", zap.NewNop()), "req-1"},
+		{"background job", context.Background(), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			c := NewWithTransport(zap.NewNop(), nil, roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				got = r.Header.Values(logging.RequestIDHeader)
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+			}))
+
+			var resp struct{}
+			if err := c.SendRequest(tt.ctx, nil, "get", "http://localhost:8082/api/v1/allsongs", &resp); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("%s = %v, want %q", logging.RequestIDHeader, got, tt.want)
+			}
+		})
+	}
+}
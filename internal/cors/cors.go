@@ -0,0 +1,201 @@
+package cors
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config says which cross origin requests browsers are allowed to make
+type Config struct {
+	// AllowedOrigins are origins like http://localhost:8081, * allows any origin
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders are request headers client may send, * allows any header
+	AllowedHeaders []string
+	// ExposedHeaders are response headers client scripts may read
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache preflight answer
+	MaxAge time.Duration
+}
+
+// DefaultConfig allows frontend dev server
+var DefaultConfig = Config{
+	AllowedOrigins: []string{"http://localhost:8081"},
+	AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPatch, http.MethodDelete},
+	AllowedHeaders: []string{"Content-Type", "Authorization", "X-Request-ID"},
+	ExposedHeaders: []string{"X-Request-ID"},
+	MaxAge:         10 * time.Minute,
+}
+
+// ConfigFromEnv overrides DefaultConfig with env vars. Lists are comma separated.
+//
+//	CORS_ALLOWED_ORIGINS, CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS, CORS_EXPOSED_HEADERS
+//	CORS_ALLOW_CREDENTIALS  true or false
+//	CORS_MAX_AGE            duration like 10m
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig
+	for env, dst := range map[string]*[]string{
+		"CORS_ALLOWED_ORIGINS": &config.AllowedOrigins,
+		"CORS_ALLOWED_METHODS": &config.AllowedMethods,
+		"CORS_ALLOWED_HEADERS": &config.AllowedHeaders,
+		"CORS_EXPOSED_HEADERS": &config.ExposedHeaders,
+	} {
+		if value, ok := os.LookupEnv(env); ok {
+			*dst = splitList(value)
+		}
+	}
+
+	var err error
+	if value := os.Getenv("CORS_ALLOW_CREDENTIALS"); value != "" {
+		config.AllowCredentials, err = strconv.ParseBool(value)
+		if err != nil {
+			return config, err
+		}
+	}
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		config.MaxAge, err = time.ParseDuration(value)
+		if err != nil {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+type cors struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	methods     map[string]bool
+	anyHeader   bool
+	headers     map[string]bool
+	credentials bool
+	// values of response headers, they are the same for every request
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+
+	next http.Handler
+}
+
+// Handler answers preflight requests and adds CORS headers to answers of next
+func Handler(config Config, next http.Handler) http.Handler {
+	c := &cors{
+		origins:       make(map[string]bool),
+		methods:       make(map[string]bool),
+		headers:       make(map[string]bool),
+		credentials:   config.AllowCredentials,
+		allowMethods:  strings.Join(config.AllowedMethods, ", "),
+		exposeHeaders: strings.Join(config.ExposedHeaders, ", "),
+		next:          next,
+	}
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.origins[strings.ToLower(origin)] = true
+	}
+	for _, method := range config.AllowedMethods {
+		c.methods[strings.ToUpper(method)] = true
+	}
+	var headers []string
+	for _, header := range config.AllowedHeaders {
+		if header == "*" {
+			c.anyHeader = true
+			continue
+		}
+		c.headers[http.CanonicalHeaderKey(header)] = true
+		headers = append(headers, header)
+	}
+	c.allowHeaders = strings.Join(headers, ", ")
+	if config.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+	return c
+}
+
+func (c *cors) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != "" {
+		c.preflight(w, r, origin)
+		return
+	}
+
+	// answer depends on origin, caches should not mix answers of different origins
+	w.Header().Add("Vary", "Origin")
+	if origin != "" && c.originAllowed(origin) {
+		c.allowOrigin(w, origin)
+		if c.exposeHeaders != "" {
+			w.Header().Set("Access-Control-Expose-Headers", c.exposeHeaders)
+		}
+	}
+	c.next.ServeHTTP(w, r)
+}
+
+// preflight answers browser asking whether request may be sent, without CORS headers answer means no
+func (c *cors) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	requested := splitList(r.Header.Get("Access-Control-Request-Headers"))
+	if !c.originAllowed(origin) || !c.methods[method] || !c.headersAllowed(requested) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.allowOrigin(w, origin)
+	header.Set("Access-Control-Allow-Methods", c.allowMethods)
+	if c.anyHeader && len(requested) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	} else if c.allowHeaders != "" {
+		header.Set("Access-Control-Allow-Headers", c.allowHeaders)
+	}
+	if c.maxAge != "" {
+		header.Set("Access-Control-Max-Age", c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *cors) originAllowed(origin string) bool {
+	return c.anyOrigin || c.origins[strings.ToLower(origin)]
+}
+
+func (c *cors) headersAllowed(headers []string) bool {
+	if c.anyHeader {
+		return true
+	}
+	for _, header := range headers {
+		if !c.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+// allowOrigin sets allowed origin, browsers reject * for requests with credentials so origin is echoed then
+func (c *cors) allowOrigin(w http.ResponseWriter, origin string) {
+	if c.anyOrigin && !c.credentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
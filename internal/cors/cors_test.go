@@ -0,0 +1,266 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	anyOrigin := DefaultConfig
+	anyOrigin.AllowedOrigins = []string{"*"}
+	credentials := anyOrigin
+	credentials.AllowCredentials = true
+	anyHeader := DefaultConfig
+	anyHeader.AllowedHeaders = []string{"*"}
+
+	tests := []struct {
+		name    string
+		config  Config
+		method  string
+		headers map[string]string
+		// wantNext is whether request is passed to next handler
+		wantNext   bool
+		wantStatus int
+		want       map[string]string
+		wantVary   []string
+	}{
+		{
+			name:       "same origin request",
+			config:     DefaultConfig,
+			method:     http.MethodGet,
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{"Access-Control-Allow-Origin": ""},
+			wantVary:   []string{"Origin"},
+		},
+		{
+			name:       "allowed origin",
+			config:     DefaultConfig,
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "http://LOCALHOST:8081"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "http://LOCALHOST:8081",
+				"Access-Control-Expose-Headers":    "X-Request-ID",
+				"Access-Control-Allow-Credentials": "",
+			},
+			wantVary: []string{"Origin"},
+		},
+		{
+			name:       "other origin",
+			config:     DefaultConfig,
+			method:     http.MethodPost,
+			headers:    map[string]string{"Origin": "http://evil.example"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":   "",
+				"Access-Control-Expose-Headers": "",
+			},
+			wantVary: []string{"Origin"},
+		},
+		{
+			name:       "any origin",
+			config:     anyOrigin,
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "http://example.com"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{"Access-Control-Allow-Origin": "*"},
+			wantVary:   []string{"Origin"},
+		},
+		{
+			name:       "any origin with credentials echoes origin",
+			config:     credentials,
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "http://example.com"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "http://example.com",
+				"Access-Control-Allow-Credentials": "true",
+			},
+			wantVary: []string{"Origin"},
+		},
+		{
+			name:   "preflight",
+			config: DefaultConfig,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "http://localhost:8081",
+				"Access-Control-Request-Method":  "patch",
+				"Access-Control-Request-Headers": "content-type, x-request-id",
+			},
+			wantStatus: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "http://localhost:8081",
+				"Access-Control-Allow-Methods": "GET, HEAD, POST, PATCH, DELETE",
+				"Access-Control-Allow-Headers": "Content-Type, Authorization, X-Request-ID",
+				"Access-Control-Max-Age":       "600",
+			},
+			wantVary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:   "preflight with credentials",
+			config: credentials,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "http://example.com",
+				"Access-Control-Request-Method": "DELETE",
+			},
+			wantStatus: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "http://example.com",
+				"Access-Control-Allow-Credentials": "true",
+			},
+			wantVary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:   "preflight from other origin",
+			config: DefaultConfig,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "http://evil.example",
+				"Access-Control-Request-Method": "GET",
+			},
+			wantStatus: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+			wantVary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:   "preflight with method not allowed",
+			config: DefaultConfig,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "http://localhost:8081",
+				"Access-Control-Request-Method": "PUT",
+			},
+			wantStatus: http.StatusNoContent,
+			want:       map[string]string{"Access-Control-Allow-Origin": ""},
+			wantVary:   []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:   "preflight with header not allowed",
+			config: DefaultConfig,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "http://localhost:8081",
+				"Access-Control-Request-Method":  "GET",
+				"Access-Control-Request-Headers": "Content-Type, X-Debug",
+			},
+			wantStatus: http.StatusNoContent,
+			want:       map[string]string{"Access-Control-Allow-Origin": ""},
+			wantVary:   []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:   "preflight with any header echoes requested",
+			config: anyHeader,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "http://localhost:8081",
+				"Access-Control-Request-Method":  "GET",
+				"Access-Control-Request-Headers": "X-Debug, X-Trace",
+			},
+			wantStatus: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "http://localhost:8081",
+				"Access-Control-Allow-Headers": "X-Debug, X-Trace",
+			},
+			wantVary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			// options without request method is not preflight
+			name:       "plain options",
+			config:     DefaultConfig,
+			method:     http.MethodOptions,
+			headers:    map[string]string{"Origin": "http://localhost:8081"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{"Access-Control-Allow-Origin": "http://localhost:8081"},
+			wantVary:   []string{"Origin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := Handler(tt.config, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+			r := httptest.NewRequest(tt.method, "/allsongs", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if called != tt.wantNext {
+				t.Errorf("next called = %v, want %v", called, tt.wantNext)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			for key, want := range tt.want {
+				if got := w.Header().Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			if got := w.Header().Values("Vary"); !reflect.DeepEqual(got, tt.wantVary) {
+				t.Errorf("Vary = %v, want %v", got, tt.wantVary)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    func(*Config)
+		wantErr bool
+	}{
+		{name: "defaults", want: func(*Config) {}},
+		{
+			name: "lists and values",
+			env: map[string]string{
+				"CORS_ALLOWED_ORIGINS":   " https://a.example , ,https://b.example",
+				"CORS_ALLOWED_HEADERS":   "",
+				"CORS_ALLOW_CREDENTIALS": "true",
+				"CORS_MAX_AGE":           "1h",
+			},
+			want: func(c *Config) {
+				c.AllowedOrigins = []string{"https://a.example", "https://b.example"}
+				c.AllowedHeaders = nil
+				c.AllowCredentials = true
+				c.MaxAge = time.Hour
+			},
+		},
+		{name: "bad credentials", env: map[string]string{"CORS_ALLOW_CREDENTIALS": "sometimes"}, wantErr: true},
+		{name: "bad max age", env: map[string]string{"CORS_MAX_AGE": "10"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			got, err := ConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := DefaultConfig
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ConfigFromEnv() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/metrics"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/router"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+// artwork names are content hashes so served images never change
+const artworkCacheControl = "public, max-age=31536000, immutable"
+
+func (h *Handlers) UploadArtwork(w http.ResponseWriter, r *http.Request) {
+	var req structs.UploadArtworkReq
+	var resp structs.UploadArtworkResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.UploadArtwork(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got UploadArtwork() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetArtwork(w http.ResponseWriter, r *http.Request) {
+	id := router.Param(r, "name")
+	etag := `"` + id + `"`
+	// artwork never changes, so client having its etag can reuse cached copy
+	cached := r.Header.Get("If-None-Match") == etag
+	metrics.CacheLookup(metrics.CacheArtwork, cached)
+	if cached {
+		// 304 carries the same headers, so client keeps caching the copy it has
+		w.Header().Set("Cache-Control", artworkCacheControl)
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := h.s.GetArtwork(r.Context(), id)
+	if err != nil {
+		h.log(r).Error("got GetArtwork() error", zap.Error(err), zap.Any("id", id))
+		sendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(data))
+	w.Header().Set("Cache-Control", artworkCacheControl)
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
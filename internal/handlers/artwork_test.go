@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetArtwork(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{"not cached", "", http.StatusOK},
+		{"cached", `"abc_300.jpg"`, http.StatusNotModified},
+		{"other etag", `"def_300.jpg"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(zap.NewNop(), &fakeService{})
+			h.InitHandlers()
+			r := httptest.NewRequest(http.MethodGet, "/artwork/abc_300.jpg", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			h.Handler().ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("ETag"); got != `"abc_300.jpg"` {
+				t.Errorf("ETag = %q", got)
+			}
+			if got := w.Header().Get("Cache-Control"); got != artworkCacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, artworkCacheControl)
+			}
+		})
+	}
+}
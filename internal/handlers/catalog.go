@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) NewArtist(w http.ResponseWriter, r *http.Request) {
+	var req structs.NewArtistReq
+	var resp structs.NewArtistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.NewArtist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got NewArtist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetArtist(w http.ResponseWriter, r *http.Request) {
+	req := structs.GetArtistReq{
+		ID:   r.URL.Query().Get("id"),
+		Name: r.URL.Query().Get("name"),
+	}
+
+	resp, err := h.s.GetArtist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetArtist() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) NewAlbum(w http.ResponseWriter, r *http.Request) {
+	var req structs.NewAlbumReq
+	var resp structs.NewAlbumResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.NewAlbum(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got NewAlbum() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetArtistAlbums(w http.ResponseWriter, r *http.Request) {
+	req := structs.GetArtistAlbumsReq{ArtistID: r.URL.Query().Get("id")}
+
+	resp, err := h.s.GetArtistAlbums(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetArtistAlbums() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetAlbumTracks(w http.ResponseWriter, r *http.Request) {
+	req := structs.GetAlbumTracksReq{AlbumID: r.URL.Query().Get("id")}
+
+	resp, err := h.s.GetAlbumTracks(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetAlbumTracks() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
@@ -1,102 +1,355 @@
 package handlers
 
 import (
-	"encoding/json"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	structs2 "github.com/supperdoggy/spotify-web-project/spotify-auth/shared/structs"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/hls"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/logging"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/metrics"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/playlistfmt"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/router"
 	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/service"
 	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
 	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
 	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
 	"go.uber.org/zap"
-	"io/ioutil"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
 )
 
 type Handlers struct {
 	logger *zap.Logger
 	s      service.IService
+	router *router.Router
+	// docs describe registered routes by "METHOD pattern"
+	docs map[string]apiDoc
+	// openapi is /openapi.json document, it is built once routes are registered
+	openapi []byte
 }
 
 func NewHandlers(l *zap.Logger, s service.IService) *Handlers {
-	return &Handlers{logger: l, s: s}
+	return &Handlers{logger: l, s: s, router: router.New(), docs: make(map[string]apiDoc)}
 }
 
 func (h *Handlers) InitHandlers() {
-	http.HandleFunc("/", h.GetSegment)
-	http.HandleFunc("/api/v1/newsong", h.createNewSong)
-	http.HandleFunc("/allsongs", h.getSongs)
-	http.HandleFunc("/login", h.Login)
-	http.HandleFunc("/register", h.Register)
-
-	// playlists
-	http.HandleFunc("/all_user_playlists", h.AllUserPlaylists)
-	http.HandleFunc("/get_playlist", h.GetUserPlaylist)
-	http.HandleFunc("/add_song_to_playlist", h.AddSongPlaylist)
-	http.HandleFunc("/remove_song_from_playlist", h.RemoveSongFromPlaylist)
-	http.HandleFunc("/new_playlist", h.NewPlaylist)
-	http.HandleFunc("/delete_playlist", h.DeletePlaylist)
+	h.router.NotFound = instrument("not_found", logRequests(h.logger, "not_found", http.HandlerFunc(h.notFound)))
+	h.router.MethodNotAllowed = instrument("method_not_allowed", logRequests(h.logger, "method_not_allowed", http.HandlerFunc(h.methodNotAllowed)))
+
+	h.handle(http.MethodGet, "/segments/{id}", h.GetSegment, apiDoc{Summary: "HLS playlist or segment of song", Query: []string{"user_id"}, Produces: []string{"application/vnd.apple.mpegurl", "video/mp2t"}})
+	h.handle(http.MethodPost, "/api/v1/newsong", h.createNewSong, apiDoc{Summary: "Upload song", Body: structs.CreateNewSongReq{}, Resp: structs.CreateSongResp{}})
+	h.handle(http.MethodPost, "/api/v1/update_song", h.UpdateSong, apiDoc{Summary: "Update song metadata", Body: structs.UpdateSongReq{}, Resp: structs.UpdateSongResp{}})
+	h.handle(http.MethodPost, "/api/v1/delete_song", h.DeleteSong, apiDoc{Summary: "Delete song", Body: structs.DeleteSongReq{}, Resp: structs.DeleteSongResp{}})
+	h.handle(http.MethodGet, "/allsongs", h.AllSongs, apiDoc{
+		Summary: "All songs, or page of songs like /api/v2/songs when any query param is sent",
+		Query:   songsPageParams,
+		Resp:    oneOf{structsDB.GetAllSongsResp{}, structs.GetSongsResp{}},
+	})
+	h.handle(http.MethodGet, "/search", h.Search, apiDoc{Summary: "Search songs, artists and albums", Query: []string{"q", "limit"}, Resp: structs.SearchResp{}})
+	// artists and albums
+	h.handle(http.MethodPost, "/api/v1/new_artist", h.NewArtist, apiDoc{Summary: "Create artist", Body: structs.NewArtistReq{}, Resp: structs.NewArtistResp{}})
+	h.handle(http.MethodPost, "/api/v1/new_album", h.NewAlbum, apiDoc{Summary: "Create album", Body: structs.NewAlbumReq{}, Resp: structs.NewAlbumResp{}})
+	h.handle(http.MethodGet, "/artist", h.GetArtist, apiDoc{Summary: "Artist by id or name", Query: []string{"id", "name"}, Resp: structs.GetArtistResp{}})
+	h.handle(http.MethodGet, "/artist_albums", h.GetArtistAlbums, apiDoc{Summary: "Albums of artist", Query: []string{"id"}, Resp: structs.GetArtistAlbumsResp{}})
+	h.handle(http.MethodGet, "/album_tracks", h.GetAlbumTracks, apiDoc{Summary: "Tracks of album", Query: []string{"id"}, Resp: structs.GetAlbumTracksResp{}})
+
+	// artwork
+	h.handle(http.MethodPost, "/api/v1/upload_artwork", h.UploadArtwork, apiDoc{Summary: "Upload artwork of song or album, playlist image is set by update_playlist", Body: structs.UploadArtworkReq{}, Resp: structs.UploadArtworkResp{}})
+	h.handle(http.MethodGet, "/artwork/{name}", h.GetArtwork, apiDoc{Summary: "Artwork image", Produces: []string{"image/*"}})
+
+	// listening history
+	h.handle(http.MethodPost, "/api/v1/play_event", h.RecordPlayEvent, apiDoc{Summary: "Record play event", Body: structs.PlayEventReq{}, Resp: structs.PlayEventResp{}})
+	h.handle(http.MethodGet, "/recently_played", h.GetRecentlyPlayed, apiDoc{Summary: "Recently played songs of user", Query: []string{"user_id", "limit"}, Resp: structs.RecentlyPlayedResp{}})
+	h.handle(http.MethodGet, "/top_tracks", h.GetTopTracks, apiDoc{Summary: "Most played songs of user", Query: []string{"user_id", "range", "limit"}, Resp: structs.TopTracksResp{}})
+	h.handle(http.MethodGet, "/top_artists", h.GetTopArtists, apiDoc{Summary: "Most played artists of user", Query: []string{"user_id", "range", "limit"}, Resp: structs.TopArtistsResp{}})
+	h.handle(http.MethodGet, "/charts", h.GetCharts, apiDoc{Summary: "Most played songs of all users", Query: []string{"window", "limit"}, Resp: structs.ChartsResp{}})
+	h.handle(http.MethodGet, "/radio", h.GetRadio, apiDoc{
+		Summary: "Radio seeded by song, artist or playlist",
+		Query:   []string{"user_id", "song_id", "artist", "playlist_id", "limit"},
+		Resp:    structs.RadioResp{},
+	})
+
+	// library
+	h.handle(http.MethodPost, "/like_song", h.LikeSong, apiDoc{Summary: "Like or unlike song", Body: structs.LikeSongReq{}, Resp: structs.LikeSongResp{}})
+	h.handle(http.MethodPost, "/save_album", h.SaveAlbum, apiDoc{Summary: "Save or remove album from library", Body: structs.SaveAlbumReq{}, Resp: structs.SaveAlbumResp{}})
+	h.handle(http.MethodGet, "/liked_songs", h.GetLikedSongs, apiDoc{Summary: "Page of liked songs", Query: []string{"user_id", "cursor", "limit"}, Resp: structs.GetLikedSongsResp{}})
+	h.handle(http.MethodGet, "/saved_albums", h.GetSavedAlbums, apiDoc{Summary: "Page of saved albums", Query: []string{"user_id", "cursor", "limit"}, Resp: structs.GetSavedAlbumsResp{}})
+	h.handle(http.MethodGet, "/are_songs_liked", h.AreSongsLiked, apiDoc{Summary: "Which of comma separated songs in ids are liked", Query: []string{"user_id", "ids"}, Resp: structs.AreSongsLikedResp{}})
+
+	h.handle(http.MethodGet, "/api/v1/breakers", h.Breakers, apiDoc{Summary: "Circuit breaker states of db and auth", Resp: map[string]string{}})
+	// metrics of scrapes are not recorded
+	h.router.Handle(http.MethodGet, "/metrics", promhttp.Handler())
+	h.document(http.MethodGet, "/metrics", apiDoc{Summary: "Prometheus metrics", Produces: []string{"text/plain"}})
+	h.handle(http.MethodGet, "/healthz", h.Healthz, apiDoc{Summary: "Liveness", Resp: structs.HealthResp{}})
+	h.handle(http.MethodGet, "/readyz", h.Readyz, apiDoc{Summary: "Readiness of every dependency, 503 when not ready", Resp: structs.ReadinessResp{}})
+
+	h.handle(http.MethodPost, "/login", h.Login, apiDoc{Summary: "Log in", Body: structs2.LoginReq{}, Resp: structs2.LoginResp{}})
+	h.handle(http.MethodPost, "/register", h.Register, apiDoc{Summary: "Register user", Body: structs2.RegisterReq{}, Resp: structs2.NewTokenResp{}})
+
+	// playlists, reads with json body are sent as GET by some clients
+	h.handleReadWithBody("/all_user_playlists", h.AllUserPlaylists, apiDoc{Summary: "Playlists of user", Body: structsDB.GetUserAllPlaylistsReq{}, Resp: structsDB.GetUserAllPlaylistsResp{}})
+	h.handleReadWithBody("/get_playlist", h.GetUserPlaylist, apiDoc{Summary: "Playlist", Body: structsDB.GetPlaylistReq{}, Resp: structsDB.GetPlaylistResp{}})
+	h.handle(http.MethodPost, "/add_song_to_playlist", h.AddSongPlaylist, apiDoc{Summary: "Add song to playlist", Body: structsDB.AddSongToUserPlaylistReq{}, Resp: structsDB.AddSongToUserPlaylistResp{}})
+	h.handle(http.MethodPost, "/remove_song_from_playlist", h.RemoveSongFromPlaylist, apiDoc{Summary: "Remove song from playlist", Body: structsDB.RemoveSongFromUserPlaylistReq{}, Resp: structsDB.RemoveSongFromUserPlaylistResp{}})
+	h.handle(http.MethodPost, "/new_playlist", h.NewPlaylist, apiDoc{Summary: "Create playlist", Body: structsDB.NewPlaylistReq{}, Resp: structsDB.NewPlaylistResp{}})
+	h.handle(http.MethodPost, "/delete_playlist", h.DeletePlaylist, apiDoc{Summary: "Delete playlist", Body: structsDB.DeleteUserPlaylistReq{}, Resp: structsDB.DeleteUserPlaylistResp{}})
+	h.handle(http.MethodPost, "/update_playlist", h.UpdatePlaylist, apiDoc{Summary: "Update playlist details", Body: structs.UpdatePlaylistReq{}, Resp: structs.UpdatePlaylistResp{}})
+	h.handle(http.MethodPost, "/move_song_in_playlist", h.MoveSongInPlaylist, apiDoc{Summary: "Move song to position in playlist", Body: structs.MoveSongInPlaylistReq{}, Resp: structs.MoveSongInPlaylistResp{}})
+	h.handle(http.MethodPost, "/add_songs_to_playlist", h.AddSongsToPlaylist, apiDoc{Summary: "Add songs to playlist", Body: structs.AddSongsToPlaylistReq{}, Resp: structs.AddSongsToPlaylistResp{}})
+	h.handle(http.MethodPost, "/remove_songs_from_playlist", h.RemoveSongsFromPlaylist, apiDoc{Summary: "Remove songs from playlist", Body: structs.RemoveSongsFromPlaylistReq{}, Resp: structs.RemoveSongsFromPlaylistResp{}})
+	h.handle(http.MethodPost, "/set_playlist_visibility", h.SetPlaylistVisibility, apiDoc{Summary: "Make playlist public or private", Body: structs.SetPlaylistVisibilityReq{}, Resp: structs.SetPlaylistVisibilityResp{}})
+	h.handle(http.MethodPost, "/share_playlist", h.SharePlaylist, apiDoc{Summary: "Share playlist with user", Body: structs.SharePlaylistReq{}, Resp: structs.SharePlaylistResp{}})
+	h.handle(http.MethodPost, "/follow_playlist", h.FollowPlaylist, apiDoc{Summary: "Follow or unfollow playlist", Body: structs.FollowPlaylistReq{}, Resp: structs.FollowPlaylistResp{}})
+	h.handleReadWithBody("/playlist_history", h.GetPlaylistHistory, apiDoc{Summary: "Versions of playlist", Body: structs.GetPlaylistHistoryReq{}, Resp: structs.GetPlaylistHistoryResp{}})
+	h.handle(http.MethodPost, "/restore_playlist_version", h.RestorePlaylistVersion, apiDoc{Summary: "Restore playlist version", Body: structs.RestorePlaylistVersionReq{}, Resp: structs.RestorePlaylistVersionResp{}})
+	h.handle(http.MethodGet, "/playlist_stream.m3u8", h.GetPlaylistStream, apiDoc{Summary: "Playlist as one HLS stream", Query: []string{"playlist_id", "user_id"}, Produces: []string{"application/vnd.apple.mpegurl"}})
+	h.handle(http.MethodGet, "/export_playlist", h.ExportPlaylist, apiDoc{
+		Summary:  "Playlist file in m3u, m3u8, xspf or json format",
+		Query:    []string{"user_id", "playlist_id", "format"},
+		Produces: []string{playlistfmt.ContentType(playlistfmt.FormatM3U), playlistfmt.ContentType(playlistfmt.FormatXSPF), "application/json"},
+	})
+	h.handle(http.MethodPost, "/import_playlist", h.ImportPlaylist, apiDoc{Summary: "Import playlist file", Body: structs.ImportPlaylistReq{}, Resp: structs.ImportPlaylistResp{}})
+
+	h.initV2()
+
+	h.initOpenAPI()
+}
+
+// Handler returns router with all routes, InitHandlers has to be called first
+func (h *Handlers) Handler() http.Handler {
+	return h.router
+}
+
+// handle registers handler on route with request metrics, tracing and access log,
+// doc describes the route in /openapi.json
+func (h *Handlers) handle(method, pattern string, handler http.HandlerFunc, doc apiDoc) {
+	h.router.Handle(method, pattern, instrument(pattern, logRequests(h.logger, pattern, handler)))
+	h.document(method, pattern, doc)
+}
+
+func (h *Handlers) handleReadWithBody(pattern string, handler http.HandlerFunc, doc apiDoc) {
+	h.handle(http.MethodGet, pattern, handler, doc)
+	h.handle(http.MethodPost, pattern, handler, doc)
+}
+
+// document sets description of route in /openapi.json
+func (h *Handlers) document(method, pattern string, doc apiDoc) {
+	h.docs[method+" "+pattern] = doc
+}
+
+// log returns logger of request tagged with its request id
+func (h *Handlers) log(r *http.Request) *zap.Logger {
+	return logging.Logger(r.Context(), h.logger)
+}
+
+// codeMethodNotAllowed is code of 405 answers, service never returns it
+const codeMethodNotAllowed = "method_not_allowed"
+
+// sendError answers with error envelope, status is picked by code of err
+func sendError(w http.ResponseWriter, err error) {
+	code := apperr.CodeOf(err)
+	utils.SendJson(w, structs.ErrorResp{Error: err.Error(), Code: string(code)}, apperr.Status(code))
+}
+
+func (h *Handlers) notFound(w http.ResponseWriter, r *http.Request) {
+	// songs saved before segments got own prefix have m3u8 path in root
+	if target, ok := legacySegmentPath(r); ok {
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+	sendError(w, apperr.New(apperr.NotFound, "no route for "+r.URL.Path))
 }
 
-// addHeaders will act as middleware to give us CORS support
-func addHeaders(h http.Handler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		h.ServeHTTP(w, r)
+// legacySegmentPath returns /segments path of m3u8 or ts file requested from root
+func legacySegmentPath(r *http.Request) (string, bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return "", false
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	if ext := path.Ext(name); ext != ".m3u8" && ext != ".ts" {
+		return "", false
 	}
+	target := "/segments/" + name
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	return target, true
+}
+
+func (h *Handlers) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	utils.SendJson(w, structs.ErrorResp{
+		Error: r.Method + " is not allowed, use " + w.Header().Get("Allow"),
+		Code:  codeMethodNotAllowed,
+	}, http.StatusMethodNotAllowed)
+}
+
+// Breakers reports circuit breaker states of db and auth
+func (h *Handlers) Breakers(w http.ResponseWriter, r *http.Request) {
+	utils.SendJson(w, h.s.DownstreamStates(r.Context()), http.StatusOK)
 }
 
 func (h *Handlers) GetSegment(writer http.ResponseWriter, request *http.Request) {
-	// maybe add specific access control origin here?
-	writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:8081")
-	id := request.RequestURI[1:]
-	resp, err := h.s.GetSegment(id)
+	id := router.Param(request, "id")
+	if ext := path.Ext(id); ext != ".m3u8" && ext != ".ts" {
+		h.notFound(writer, request)
+		return
+	}
+	resp, err := h.s.GetSegment(request.Context(), id)
 	if err != nil {
-		writer.WriteHeader(http.StatusBadRequest)
+		h.log(request).Error("got GetSegment() error", zap.Error(err), zap.String("id", id))
+		sendError(writer, err)
 		return
 	}
+	userID := request.URL.Query().Get("user_id")
+	h.s.TrackSegmentFetch(request.Context(), userID, request.RemoteAddr, id)
+	// segments of song are fetched with user id too, so play tracker knows who listens
+	if path.Ext(id) == ".m3u8" && userID != "" {
+		resp = hls.AddURIQuery(resp, "user_id="+url.QueryEscape(userID))
+	}
+	metrics.SegmentBytes.Add(float64(len(resp)))
 
 	writer.WriteHeader(http.StatusOK)
 	writer.Write(resp)
 }
 
-func (h *Handlers) getSongs(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	var status = http.StatusOK
-	// getNames
-	resp, err := h.s.GetAllSongs()
+// songsPageParams are query params of songs page, without them /allsongs answers with all songs
+var songsPageParams = []string{"cursor", "limit", "sort_by", "order", "band", "album", "year_from", "year_to"}
+
+// AllSongs keeps answering old clients with all songs, clients sending any of
+// songsPageParams get pages like from /api/v2/songs
+func (h *Handlers) AllSongs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	for _, param := range songsPageParams {
+		if query.Has(param) {
+			h.getSongs(w, r)
+			return
+		}
+	}
+
+	resp, err := h.s.GetAllSongs(r.Context())
 	if err != nil {
-		h.logger.Error("error getting all songs", zap.Error(err))
-		status = http.StatusBadRequest
+		h.log(r).Error("error getting all songs", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) getSongs(w http.ResponseWriter, r *http.Request) {
+	var resp structs.GetSongsResp
+	query := r.URL.Query()
+	req := structs.GetSongsReq{
+		Cursor: query.Get("cursor"),
+		SortBy: query.Get("sort_by"),
+		Order:  query.Get("order"),
+		Band:   query.Get("band"),
+		Album:  query.Get("album"),
+	}
+
+	var err error
+	for param, dst := range map[string]*int{"limit": &req.Limit, "year_from": &req.YearFrom, "year_to": &req.YearTo} {
+		if query.Get(param) == "" {
+			continue
+		}
+		*dst, err = strconv.Atoi(query.Get(param))
+		if err != nil {
+			h.log(r).Error("error parsing query param", zap.Error(err), zap.String("param", param))
+			sendError(w, apperr.New(apperr.Validation, "invalid "+param))
+			return
+		}
 	}
-	data, err := json.Marshal(resp)
+
+	resp, err = h.s.GetSongs(r.Context(), req)
 	if err != nil {
-		h.logger.Error("error marshaling response", zap.Error(err), zap.Any("data", resp))
+		h.log(r).Error("error getting songs", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
 		return
 	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) Search(w http.ResponseWriter, r *http.Request) {
+	req := structs.SearchReq{Query: r.URL.Query().Get("q")}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		var err error
+		req.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			h.log(r).Error("error parsing limit", zap.Error(err))
+			sendError(w, apperr.New(apperr.Validation, "invalid limit"))
+			return
+		}
+	}
 
-	w.WriteHeader(status)
-	w.Write(data)
+	resp, err := h.s.Search(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got Search() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
 }
 
 func (h *Handlers) createNewSong(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:8081")
 	var req structs.CreateNewSongReq
-	data, err := ioutil.ReadAll(r.Body)
+	var resp structs.CreateSongResp
+	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		w.Write([]byte("{'error':'error parsing body'}"))
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	err = json.Unmarshal(data, &req)
+	resp.Song, err = h.s.CreateNewSong(r.Context(), req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		w.Write([]byte("{'error':'error unmarshalling req'}"))
+		h.log(r).Error("got CreateNewSong() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
 
-	err = h.s.CreateNewSong(req)
+func (h *Handlers) UpdateSong(w http.ResponseWriter, r *http.Request) {
+	var req structs.UpdateSongReq
+	var resp structs.UpdateSongResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.UpdateSong(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got UpdateSong() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
 
+func (h *Handlers) DeleteSong(w http.ResponseWriter, r *http.Request) {
+	var req structs.DeleteSongReq
+	var resp structs.DeleteSongResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.DeleteSong(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got DeleteSong() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
 }
 
 func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
@@ -104,16 +357,15 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 	var resp structs2.LoginResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.Login(req)
+	resp, err = h.s.Login(r.Context(), req)
 	if err != nil {
-		h.logger.Error("got Login() error", zap.Error(err))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("got Login() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 	utils.SendJson(w, resp, http.StatusOK)
@@ -124,16 +376,15 @@ func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) {
 	var resp structs2.NewTokenResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.Register(req)
+	resp, err = h.s.Register(r.Context(), req)
 	if err != nil {
-		h.logger.Error("gor Register() error", zap.Error(err))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("got Register() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 	utils.SendJson(w, resp, http.StatusOK)
@@ -144,16 +395,15 @@ func (h *Handlers) NewPlaylist(w http.ResponseWriter, r *http.Request) {
 	var resp structsDB.NewPlaylistResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.NewPlaylist(req)
+	resp, err = h.s.NewPlaylist(r.Context(), req)
 	if err != nil {
-		h.logger.Error("gor Register() error", zap.Error(err))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("got NewPlaylist() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 	utils.SendJson(w, resp, http.StatusOK)
@@ -164,16 +414,15 @@ func (h *Handlers) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
 	var resp structsDB.DeleteUserPlaylistResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.DeletePlaylist(req)
+	resp, err = h.s.DeletePlaylist(r.Context(), req)
 	if err != nil {
-		h.logger.Error("gor Register() error", zap.Error(err))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("got DeletePlaylist() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 	utils.SendJson(w, resp, http.StatusOK)
@@ -184,16 +433,15 @@ func (h *Handlers) GetUserPlaylist(w http.ResponseWriter, r *http.Request) {
 	var resp structsDB.GetPlaylistResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.GetPlaylist(req)
+	resp, err = h.s.GetPlaylist(r.Context(), req)
 	if err != nil {
-		h.logger.Error("gor Register() error", zap.Error(err))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("got GetPlaylist() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 	utils.SendJson(w, resp, http.StatusOK)
@@ -204,16 +452,15 @@ func (h *Handlers) AddSongPlaylist(w http.ResponseWriter, r *http.Request) {
 	var resp structsDB.AddSongToUserPlaylistResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.AddSongToPlaylist(req)
+	resp, err = h.s.AddSongToPlaylist(r.Context(), req)
 	if err != nil {
-		h.logger.Error("gor Register() error", zap.Error(err))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("got AddSongToPlaylist() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 	utils.SendJson(w, resp, http.StatusOK)
@@ -224,16 +471,15 @@ func (h *Handlers) RemoveSongFromPlaylist(w http.ResponseWriter, r *http.Request
 	var resp structsDB.RemoveSongFromUserPlaylistResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error reading body", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.RemoveSongFromPlaylist(req)
+	resp, err = h.s.RemoveSongFromPlaylist(r.Context(), req)
 	if err != nil {
-		h.logger.Error("gor Register() error", zap.Error(err))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("got RemoveSongFromPlaylist() error", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 	utils.SendJson(w, resp, http.StatusOK)
@@ -244,16 +490,15 @@ func (h *Handlers) AllUserPlaylists(w http.ResponseWriter, r *http.Request) {
 	var resp structsDB.GetUserAllPlaylistsResp
 	err := utils.ParseJson(r, &req)
 	if err != nil {
-		h.logger.Error("error parsing user request", zap.Error(err))
-		resp.Error = err.Error()
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error parsing user request", zap.Error(err))
+		sendError(w, err)
 		return
 	}
 
-	resp, err = h.s.GetUserPlaylists(req)
+	resp, err = h.s.GetUserPlaylists(r.Context(), req)
 	if err != nil {
-		h.logger.Error("error getting user playlist", zap.Error(err), zap.Any("req", req))
-		utils.SendJson(w, resp, http.StatusBadRequest)
+		h.log(r).Error("error getting user playlist", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
 		return
 	}
 
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+func TestSendError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       structs.ErrorResp
+	}{
+		{"validation", apperr.New(apperr.Validation, "empty song id"), http.StatusBadRequest, structs.ErrorResp{Error: "empty song id", Code: "validation"}},
+		{"not found", apperr.Wrap(apperr.NotFound, errors.New("no song")), http.StatusNotFound, structs.ErrorResp{Error: "no song", Code: "not_found"}},
+		{"upstream", apperr.New(apperr.UpstreamUnavailable, "db is down"), http.StatusServiceUnavailable, structs.ErrorResp{Error: "db is down", Code: "upstream_unavailable"}},
+		{"unclassified", errors.New("boom"), http.StatusInternalServerError, structs.ErrorResp{Error: "boom", Code: "internal"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			sendError(rec, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("content type = %q", ct)
+			}
+			var got structs.ErrorResp
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("error decoding envelope %s: %v", rec.Body, err)
+			}
+			if got != tt.want {
+				t.Errorf("envelope = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMethodNotAllowed checks router errors use the same envelope as service errors
+func TestMethodNotAllowed(t *testing.T) {
+	h := NewHandlers(zap.NewNop(), &fakeService{})
+	h.InitHandlers()
+
+	rec := httptest.NewRecorder()
+	h.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/delete_song", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	var got structs.ErrorResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("error decoding envelope %s: %v", rec.Body, err)
+	}
+	if got.Code != codeMethodNotAllowed {
+		t.Errorf("code = %q, want %q", got.Code, codeMethodNotAllowed)
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+// Healthz answers as long as process serves requests
+func (h *Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	utils.SendJson(w, structs.HealthResp{Status: "ok"}, http.StatusOK)
+}
+
+// Readyz reports status of every dependency, 503 means requests should not be sent here
+func (h *Handlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	resp := h.s.CheckReadiness(r.Context())
+	if !resp.Ready {
+		h.log(r).Warn("service is not ready", zap.Any("dependencies", resp.Dependencies))
+		utils.SendJson(w, resp, http.StatusServiceUnavailable)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) LikeSong(w http.ResponseWriter, r *http.Request) {
+	var req structs.LikeSongReq
+	var resp structs.LikeSongResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.LikeSong(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got LikeSong() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) SaveAlbum(w http.ResponseWriter, r *http.Request) {
+	var req structs.SaveAlbumReq
+	var resp structs.SaveAlbumResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.SaveAlbum(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got SaveAlbum() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetLikedSongs(w http.ResponseWriter, r *http.Request) {
+	req := structs.LibraryPageReq{UserID: r.URL.Query().Get("user_id"), Cursor: r.URL.Query().Get("cursor")}
+	if !parseLimit(w, r, &req.Limit) {
+		return
+	}
+
+	resp, err := h.s.GetLikedSongs(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetLikedSongs() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetSavedAlbums(w http.ResponseWriter, r *http.Request) {
+	req := structs.LibraryPageReq{UserID: r.URL.Query().Get("user_id"), Cursor: r.URL.Query().Get("cursor")}
+	if !parseLimit(w, r, &req.Limit) {
+		return
+	}
+
+	resp, err := h.s.GetSavedAlbums(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetSavedAlbums() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+// AreSongsLiked takes comma separated song ids in ids query param
+func (h *Handlers) AreSongsLiked(w http.ResponseWriter, r *http.Request) {
+	req := structs.AreSongsLikedReq{UserID: r.URL.Query().Get("user_id")}
+	if ids := r.URL.Query().Get("ids"); ids != "" {
+		req.SongIDs = strings.Split(ids, ",")
+	}
+
+	resp, err := h.s.AreSongsLiked(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got AreSongsLiked() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/logging"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("github.com/supperdoggy/spotify-web-project/spotify-back/internal/handlers")
+
+// responseRecorder remembers status code and size of response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.bytes += n
+	return n, err
+}
+
+// instrument records request count and latency of route and traces request,
+// continuing trace of caller if it sent traceparent header
+func instrument(route string, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+		metrics.HTTPRequests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+		metrics.HTTPDuration.WithLabelValues(route, r.Method).Observe(time.Since(started).Seconds())
+	}
+}
+
+// logRequests assigns request id, puts logger tagged with it to request context
+// and writes one access log line per request
+func logRequests(logger *zap.Logger, route string, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		requestID := logging.NewRequestID(r.Header.Get(logging.RequestIDHeader))
+		w.Header().Set(logging.RequestIDHeader, requestID)
+
+		fields := []zap.Field{zap.String("request_id", requestID)}
+		span := trace.SpanFromContext(r.Context())
+		if span.SpanContext().HasTraceID() {
+			fields = append(fields, zap.String("trace_id", span.SpanContext().TraceID().String()))
+		}
+		span.SetAttributes(attribute.String("request.id", requestID))
+		ctx := logging.NewContext(r.Context(), requestID, logger.With(fields...))
+		// body user id is set by ParseJson
+		logging.SetUserID(ctx, r.URL.Query().Get("user_id"))
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		fields = []zap.Field{
+			zap.String("method", r.Method),
+			zap.String("route", route),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", recorder.status),
+			zap.Int("bytes", recorder.bytes),
+			zap.Duration("duration", time.Since(started)),
+			zap.String("user_id", logging.UserID(ctx)),
+			zap.String("remote_addr", r.RemoteAddr),
+		}
+		if recorder.status >= http.StatusInternalServerError {
+			logging.Logger(ctx, logger).Error("access", fields...)
+			return
+		}
+		logging.Logger(ctx, logger).Info("access", fields...)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/logging"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		target    string
+		body      string
+		status    int
+		wantLevel zapcore.Level
+		wantUser  string
+	}{
+		{name: "id sent by client", requestID: "req-1", target: "/playlist?user_id=u1", status: http.StatusOK, wantLevel: zapcore.InfoLevel, wantUser: "u1"},
+		{name: "new id", target: "/playlist", status: http.StatusNotFound, wantLevel: zapcore.InfoLevel},
+		{name: "user from body", target: "/playlist", body: `{"user_id":"u2"}`, status: http.StatusOK, wantLevel: zapcore.InfoLevel, wantUser: "u2"},
+		{name: "server error", requestID: "req-2", target: "/playlist", status: http.StatusBadGateway, wantLevel: zapcore.ErrorLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			var handlerID string
+			handler := logRequests(zap.New(core), "/playlist", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerID = logging.RequestID(r.Context())
+				if tt.body != "" {
+					var req struct{}
+					utils.ParseJson(r, &req)
+				}
+				// service logs through logger of request
+				logging.Logger(r.Context(), zap.NewNop()).Info("service call")
+				w.WriteHeader(tt.status)
+				w.Write([]byte("done"))
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.requestID != "" {
+				r.Header.Set(logging.RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			requestID := w.Header().Get(logging.RequestIDHeader)
+			if requestID == "" || (tt.requestID != "" && requestID != tt.requestID) || handlerID != requestID {
+				t.Fatalf("request id = %q, handler got %q, sent %q", requestID, handlerID, tt.requestID)
+			}
+
+			entries := logs.AllUntimed()
+			if len(entries) != 2 {
+				t.Fatalf("%d log lines, want service line and access line", len(entries))
+			}
+			for _, entry := range entries {
+				if got := entry.ContextMap()["request_id"]; got != requestID {
+					t.Errorf("%q line has request id %v", entry.Message, got)
+				}
+			}
+			access := entries[1]
+			fields := access.ContextMap()
+			if access.Message != "access" || access.Level != tt.wantLevel {
+				t.Errorf("access line %q at %s, want at %s", access.Message, access.Level, tt.wantLevel)
+			}
+			if fields["status"] != int64(tt.status) || fields["bytes"] != int64(4) || fields["route"] != "/playlist" || fields["user_id"] != tt.wantUser {
+				t.Errorf("access fields = %v", fields)
+			}
+		})
+	}
+}
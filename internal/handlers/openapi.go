@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/openapi"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/router"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+)
+
+// apiVersion is version of api described in /openapi.json
+const apiVersion = "2.0.0"
+
+// apiDoc describes route in /openapi.json, it is given next to handler in InitHandlers.
+// Body and Resp are zero values of types handler decodes and sends, schemas are
+// generated from their json tags. Resp can be oneOf when handler sends few shapes.
+type apiDoc struct {
+	Summary string
+	// Query lists query params handler reads
+	Query []string
+	Body  interface{}
+	Resp  interface{}
+	// Status of successful response, 200 when not set
+	Status int
+	// Produces lists media types of response that is not json
+	Produces []string
+}
+
+// oneOf documents response that has one of few shapes
+type oneOf []interface{}
+
+// integerParams are query params parsed as numbers
+var integerParams = map[string]bool{"limit": true, "year_from": true, "year_to": true}
+
+func routeKey(route router.Route) string {
+	return route.Method + " " + route.Pattern
+}
+
+// checkAPIDocs reports routes without docs and docs of routes that are not registered
+func checkAPIDocs(routes []router.Route, docs map[string]apiDoc) error {
+	var problems []string
+	registered := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		registered[routeKey(route)] = true
+		if _, ok := docs[routeKey(route)]; !ok {
+			problems = append(problems, routeKey(route)+" is not documented")
+		}
+	}
+	for key := range docs {
+		if !registered[key] {
+			problems = append(problems, key+" is documented but not registered")
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("openapi docs drifted from routes: %s", strings.Join(problems, "; "))
+}
+
+// buildOpenAPI writes document of routes, routes have to be checked with checkAPIDocs first
+func buildOpenAPI(routes []router.Route, docs map[string]apiDoc) *openapi.Document {
+	doc := openapi.NewDocument("spotify-back", apiVersion)
+	for _, route := range routes {
+		d := docs[routeKey(route)]
+		op := &openapi.Operation{Summary: d.Summary, Responses: make(map[string]openapi.Response)}
+
+		for _, segment := range strings.Split(route.Pattern, "/") {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				op.Parameters = append(op.Parameters, openapi.Parameter{
+					Name:     strings.Trim(segment, "{}"),
+					In:       "path",
+					Required: true,
+					Schema:   &openapi.Schema{Type: "string"},
+				})
+			}
+		}
+		for _, name := range d.Query {
+			schema := &openapi.Schema{Type: "string"}
+			if integerParams[name] {
+				schema = &openapi.Schema{Type: "integer"}
+			}
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: name, In: "query", Schema: schema})
+		}
+
+		if d.Body != nil {
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaOf(d.Body)}},
+			}
+		}
+
+		status := d.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := openapi.Response{Description: http.StatusText(status)}
+		switch {
+		case len(d.Produces) > 0:
+			success.Content = make(map[string]openapi.MediaType)
+			for _, mediaType := range d.Produces {
+				success.Content[mediaType] = openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary"}}
+			}
+		case d.Resp != nil:
+			success.Content = map[string]openapi.MediaType{"application/json": {Schema: respSchema(doc, d.Resp)}}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		// every route answers errors with the same envelope, status depends on error code
+		op.Responses["default"] = openapi.Response{
+			Description: "Error",
+			Content:     map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaOf(structs.ErrorResp{})}},
+		}
+
+		doc.AddOperation(route.Method, route.Pattern, op)
+	}
+	return doc
+}
+
+func respSchema(doc *openapi.Document, resp interface{}) *openapi.Schema {
+	shapes, ok := resp.(oneOf)
+	if !ok {
+		return doc.SchemaOf(resp)
+	}
+	schema := &openapi.Schema{}
+	for _, shape := range shapes {
+		schema.OneOf = append(schema.OneOf, doc.SchemaOf(shape))
+	}
+	return schema
+}
+
+// OpenAPI serves description of all routes
+func (h *Handlers) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.openapi)
+}
+
+// initOpenAPI registers /openapi.json, it has to be called after all other routes are registered
+func (h *Handlers) initOpenAPI() {
+	h.handle(http.MethodGet, "/openapi.json", h.OpenAPI, apiDoc{Summary: "This document", Resp: openapi.Document{}})
+
+	routes := h.router.Routes()
+	if err := checkAPIDocs(routes, h.docs); err != nil {
+		panic(err)
+	}
+	data, err := json.Marshal(buildOpenAPI(routes, h.docs))
+	if err != nil {
+		panic(fmt.Sprintf("error marshalling openapi document: %v", err))
+	}
+	h.openapi = data
+}
@@ -0,0 +1,725 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	structs2 "github.com/supperdoggy/spotify-web-project/spotify-auth/shared/structs"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/openapi"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+	"go.uber.org/zap"
+)
+
+// TestRoutesMatchOpenAPI calls every route with request built from its /openapi.json
+// operation and checks that service got every documented value, that nothing the
+// service got was left empty, and that response matches documented schema.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	fake := &fakeService{}
+	h := NewHandlers(zap.NewNop(), fake)
+	h.InitHandlers()
+
+	var doc openapi.Document
+	if err := json.Unmarshal(h.openapi, &doc); err != nil {
+		t.Fatalf("error decoding openapi document: %v", err)
+	}
+
+	for _, route := range h.router.Routes() {
+		op := (*doc.Paths[route.Pattern])[strings.ToLower(route.Method)]
+		if op == nil {
+			t.Errorf("%s %s: no operation in document", route.Method, route.Pattern)
+			continue
+		}
+		t.Run(route.Method+" "+route.Pattern, func(t *testing.T) {
+			checkOperation(t, h, &doc, route.Method, route.Pattern, op, true)
+		})
+	}
+
+	// without query /allsongs answers with other shape of the same documented oneOf
+	t.Run("GET /allsongs without query", func(t *testing.T) {
+		op := (*doc.Paths["/allsongs"])["get"]
+		checkOperation(t, h, &doc, http.MethodGet, "/allsongs", op, false)
+	})
+}
+
+func TestCheckAPIDocs(t *testing.T) {
+	ok := func(http.ResponseWriter, *http.Request) {}
+	tests := []struct {
+		name    string
+		prepare func(h *Handlers)
+		wantErr bool
+	}{
+		{"documented", func(h *Handlers) { h.handle(http.MethodGet, "/a", ok, apiDoc{Summary: "a"}) }, false},
+		{"not documented", func(h *Handlers) { h.router.Handle(http.MethodGet, "/a", http.HandlerFunc(ok)) }, true},
+		{"not registered", func(h *Handlers) { h.document(http.MethodGet, "/a", apiDoc{Summary: "a"}) }, true},
+		{"other method", func(h *Handlers) {
+			h.router.Handle(http.MethodPost, "/a", http.HandlerFunc(ok))
+			h.document(http.MethodGet, "/a", apiDoc{Summary: "a"})
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(zap.NewNop(), &fakeService{})
+			tt.prepare(h)
+			err := checkAPIDocs(h.router.Routes(), h.docs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkAPIDocs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func checkOperation(t *testing.T, h *Handlers, doc *openapi.Document, method, pattern string, op *openapi.Operation, withQuery bool) {
+	fake := h.s.(*fakeService)
+	fake.got = nil
+
+	target := pattern
+	query := url.Values{}
+	var sent []string
+	pathParams := false
+	for _, param := range op.Parameters {
+		switch {
+		case param.In == "path":
+			// segments route serves only m3u8 and ts names
+			value := "v-" + param.Name + ".m3u8"
+			target = strings.Replace(target, "{"+param.Name+"}", value, 1)
+			pathParams = true
+			sent = append(sent, value)
+		case param.In == "query" && withQuery:
+			if param.Schema.Type == "integer" {
+				// numbers are checked by emptyLeaves
+				query.Set(param.Name, "7")
+				continue
+			}
+			query.Set(param.Name, "v-"+param.Name)
+			sent = append(sent, "v-"+param.Name)
+		}
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body []byte
+	if op.RequestBody != nil {
+		value := sample(doc, op.RequestBody.Content["application/json"].Schema, "")
+		var err error
+		if body, err = json.Marshal(value); err != nil {
+			t.Fatalf("error encoding body: %v", err)
+		}
+		for _, leaf := range sampleStrings(value) {
+			// ids in body are replaced by ids from path
+			if pathParams && strings.HasSuffix(leaf, "id") {
+				continue
+			}
+			sent = append(sent, leaf)
+		}
+	}
+
+	r := httptest.NewRequest(method, target, bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	h.Handler().ServeHTTP(w, r)
+
+	var status string
+	var success openapi.Response
+	for code, response := range op.Responses {
+		if code != "default" {
+			status, success = code, response
+		}
+	}
+	if strconv.Itoa(w.Code) != status {
+		t.Fatalf("status is %d, documented %s, body %s", w.Code, status, w.Body.String())
+	}
+
+	got := make(map[string]bool)
+	for _, req := range fake.got {
+		for _, leaf := range leafStrings(reflect.ValueOf(req)) {
+			got[leaf] = true
+		}
+		for _, field := range emptyLeaves(reflect.ValueOf(req), reflect.TypeOf(req).Name()) {
+			t.Errorf("service got empty %s, it is not filled from any documented param or body field", field)
+		}
+	}
+	for _, value := range sent {
+		if !got[value] {
+			t.Errorf("documented value %q did not reach service, it got %+v", value, fake.got)
+		}
+	}
+
+	media, ok := success.Content["application/json"]
+	if !ok || media.Schema.Format == "binary" {
+		return
+	}
+	var resp interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	for _, problem := range validate(doc, media.Schema, resp, "resp") {
+		t.Error(problem)
+	}
+}
+
+// resolve follows $ref to component schema
+func resolve(doc *openapi.Document, schema *openapi.Schema) *openapi.Schema {
+	for schema.Ref != "" {
+		schema = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// sample builds json value of schema, strings hold name of their property so they can be traced
+func sample(doc *openapi.Document, schema *openapi.Schema, name string) interface{} {
+	schema = resolve(doc, schema)
+	if len(schema.OneOf) > 0 {
+		return sample(doc, schema.OneOf[0], name)
+	}
+	switch schema.Type {
+	case "object":
+		value := make(map[string]interface{})
+		for property, propertySchema := range schema.Properties {
+			value[property] = sample(doc, propertySchema, property)
+		}
+		if schema.AdditionalProperties != nil {
+			value["key"] = sample(doc, schema.AdditionalProperties, name)
+		}
+		return value
+	case "array":
+		return []interface{}{sample(doc, schema.Items, name)}
+	case "integer":
+		return 7
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "2024-01-02T03:04:05Z"
+		case "byte":
+			return base64.StdEncoding.EncodeToString([]byte("v-" + name))
+		}
+		return "v-" + name
+	}
+	return "v-" + name
+}
+
+// sampleStrings returns strings of sample value, byte strings are decoded
+func sampleStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		var leaves []string
+		for _, item := range v {
+			leaves = append(leaves, sampleStrings(item)...)
+		}
+		return leaves
+	case []interface{}:
+		var leaves []string
+		for _, item := range v {
+			leaves = append(leaves, sampleStrings(item)...)
+		}
+		return leaves
+	case string:
+		if data, err := base64.StdEncoding.DecodeString(v); err == nil && strings.HasPrefix(string(data), "v-") {
+			return []string{string(data)}
+		}
+		if strings.HasPrefix(v, "v-") {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+// leafStrings returns every string and byte slice in v
+func leafStrings(v reflect.Value) []string {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return leafStrings(v.Elem())
+	case reflect.String:
+		return []string{v.String()}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return []string{string(v.Bytes())}
+		}
+		var leaves []string
+		for i := 0; i < v.Len(); i++ {
+			leaves = append(leaves, leafStrings(v.Index(i))...)
+		}
+		return leaves
+	case reflect.Map:
+		var leaves []string
+		for _, key := range v.MapKeys() {
+			leaves = append(leaves, leafStrings(v.MapIndex(key))...)
+		}
+		return leaves
+	case reflect.Struct:
+		var leaves []string
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				leaves = append(leaves, leafStrings(v.Field(i))...)
+			}
+		}
+		return leaves
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// emptyLeaves returns paths of zero values in v
+func emptyLeaves(v reflect.Value, path string) []string {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return []string{path}
+		}
+		return emptyLeaves(v.Elem(), path)
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return []string{path}
+		}
+		return nil
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if v.Interface().(time.Time).IsZero() {
+				return []string{path}
+			}
+			return nil
+		}
+		var leaves []string
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.IsExported() {
+				leaves = append(leaves, emptyLeaves(v.Field(i), path+"."+field.Name)...)
+			}
+		}
+		return leaves
+	}
+	if v.IsZero() {
+		return []string{path}
+	}
+	return nil
+}
+
+// validate reports where json value does not match schema
+func validate(doc *openapi.Document, schema *openapi.Schema, value interface{}, path string) []string {
+	schema = resolve(doc, schema)
+	if len(schema.OneOf) > 0 {
+		var all []string
+		for _, shape := range schema.OneOf {
+			problems := validate(doc, shape, value, path)
+			if len(problems) == 0 {
+				return nil
+			}
+			all = append(all, problems...)
+		}
+		return append([]string{path + " matches none of oneOf"}, all...)
+	}
+	if schema.Type == "" {
+		return nil
+	}
+	// nil slices, maps and pointers are written as null
+	if value == nil && (schema.Type == "object" || schema.Type == "array") {
+		return nil
+	}
+
+	wrongType := []string{path + " is not " + schema.Type}
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return wrongType
+		}
+		var problems []string
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, path+"."+name+" is documented but missing")
+			}
+		}
+		for name, item := range object {
+			itemSchema, ok := schema.Properties[name]
+			if !ok {
+				itemSchema = schema.AdditionalProperties
+			}
+			if itemSchema == nil {
+				problems = append(problems, path+"."+name+" is not documented")
+				continue
+			}
+			problems = append(problems, validate(doc, itemSchema, item, path+"."+name)...)
+		}
+		return problems
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return wrongType
+		}
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, validate(doc, schema.Items, item, path+"["+strconv.Itoa(i)+"]")...)
+		}
+		return problems
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return wrongType
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return wrongType
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return wrongType
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return wrongType
+		}
+		switch schema.Format {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, text); err != nil {
+				return []string{path + " is not date-time"}
+			}
+		case "byte":
+			if _, err := base64.StdEncoding.DecodeString(text); err != nil {
+				return []string{path + " is not base64"}
+			}
+		}
+	}
+	return nil
+}
+
+// fakeService remembers requests and names of methods it got and answers with every field filled
+type fakeService struct {
+	got   []interface{}
+	calls []string
+}
+
+func (f *fakeService) serve(req, resp interface{}) {
+	if req != nil {
+		f.got = append(f.got, req)
+	}
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		name := runtime.FuncForPC(pc).Name()
+		f.calls = append(f.calls, name[strings.LastIndex(name, ".")+1:])
+	}
+	if resp != nil {
+		fill(reflect.ValueOf(resp).Elem(), 0)
+	}
+}
+
+// fill sets every field of v to non zero value, so all of them are written to response
+func fill(v reflect.Value, depth int) {
+	if depth > 8 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), depth+1)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fill(v.Field(i), depth+1)
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), depth+1)
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		key := reflect.New(v.Type().Key()).Elem()
+		fill(key, depth+1)
+		value := reflect.New(v.Type().Elem()).Elem()
+		fill(value, depth+1)
+		v.SetMapIndex(key, value)
+	case reflect.String:
+		v.SetString("sample")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(7)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(7)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	}
+}
+
+func (f *fakeService) CreateNewSong(_ context.Context, req structs.CreateNewSongReq) (song globalStructs.Song, err error) {
+	f.serve(req, &song)
+	return
+}
+
+func (f *fakeService) GetAllSongs(context.Context) (resp structsDB.GetAllSongsResp, err error) {
+	f.serve(nil, &resp)
+	return
+}
+
+func (f *fakeService) GetSongs(_ context.Context, req structs.GetSongsReq) (resp structs.GetSongsResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetSong(_ context.Context, req structs.GetSongReq) (resp structs.GetSongResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetSegment(_ context.Context, id string) ([]byte, error) {
+	f.serve(id, nil)
+	return []byte("#EXTM3U\n"), nil
+}
+
+func (f *fakeService) Register(_ context.Context, req structs2.RegisterReq) (resp structs2.NewTokenResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) Login(_ context.Context, req structs2.LoginReq) (resp structs2.LoginResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) RemoveSongFromPlaylist(_ context.Context, req structsDB.RemoveSongFromUserPlaylistReq) (resp structsDB.RemoveSongFromUserPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetUserPlaylists(_ context.Context, req structsDB.GetUserAllPlaylistsReq) (resp structsDB.GetUserAllPlaylistsResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetPlaylist(_ context.Context, req structsDB.GetPlaylistReq) (resp structsDB.GetPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) NewPlaylist(_ context.Context, req structsDB.NewPlaylistReq) (resp structsDB.NewPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) DeletePlaylist(_ context.Context, req structsDB.DeleteUserPlaylistReq) (resp structsDB.DeleteUserPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) AddSongToPlaylist(_ context.Context, req structsDB.AddSongToUserPlaylistReq) (resp structsDB.AddSongToUserPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) UpdateSong(_ context.Context, req structs.UpdateSongReq) (resp structs.UpdateSongResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) PatchSong(_ context.Context, req structs.PatchSongReq) (resp structs.UpdateSongResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) DeleteSong(_ context.Context, req structs.DeleteSongReq) (resp structs.DeleteSongResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) Search(_ context.Context, req structs.SearchReq) (resp structs.SearchResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) NewArtist(_ context.Context, req structs.NewArtistReq) (resp structs.NewArtistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetArtist(_ context.Context, req structs.GetArtistReq) (resp structs.GetArtistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) NewAlbum(_ context.Context, req structs.NewAlbumReq) (resp structs.NewAlbumResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetArtistAlbums(_ context.Context, req structs.GetArtistAlbumsReq) (resp structs.GetArtistAlbumsResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetAlbumTracks(_ context.Context, req structs.GetAlbumTracksReq) (resp structs.GetAlbumTracksResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) UploadArtwork(_ context.Context, req structs.UploadArtworkReq) (resp structs.UploadArtworkResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetArtwork(_ context.Context, id string) ([]byte, error) {
+	f.serve(id, nil)
+	return []byte("#EXTM3U\n"), nil
+}
+
+func (f *fakeService) UpdatePlaylist(_ context.Context, req structs.UpdatePlaylistReq) (resp structs.UpdatePlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) MoveSongInPlaylist(_ context.Context, req structs.MoveSongInPlaylistReq) (resp structs.MoveSongInPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) AddSongsToPlaylist(_ context.Context, req structs.AddSongsToPlaylistReq) (resp structs.AddSongsToPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) RemoveSongsFromPlaylist(_ context.Context, req structs.RemoveSongsFromPlaylistReq) (resp structs.RemoveSongsFromPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) SetPlaylistVisibility(_ context.Context, req structs.SetPlaylistVisibilityReq) (resp structs.SetPlaylistVisibilityResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) SharePlaylist(_ context.Context, req structs.SharePlaylistReq) (resp structs.SharePlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) FollowPlaylist(_ context.Context, req structs.FollowPlaylistReq) (resp structs.FollowPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetPlaylistHistory(_ context.Context, req structs.GetPlaylistHistoryReq) (resp structs.GetPlaylistHistoryResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) RestorePlaylistVersion(_ context.Context, req structs.RestorePlaylistVersionReq) (resp structs.RestorePlaylistVersionResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetPlaylistTracks(_ context.Context, req structsDB.GetPlaylistReq) (resp structs.GetPlaylistTracksResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetPlaylistStream(_ context.Context, req structsDB.GetPlaylistReq) ([]byte, error) {
+	f.serve(req, nil)
+	return []byte("#EXTM3U\n"), nil
+}
+
+func (f *fakeService) ExportPlaylist(_ context.Context, req structs.ExportPlaylistReq) ([]byte, error) {
+	f.serve(req, nil)
+	return []byte("#EXTM3U\n"), nil
+}
+
+func (f *fakeService) ImportPlaylist(_ context.Context, req structs.ImportPlaylistReq) (resp structs.ImportPlaylistResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) RecordPlayEvent(_ context.Context, req structs.PlayEventReq) (resp structs.PlayEventResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+// segmentFetch is request of TrackSegmentFetch
+type segmentFetch struct {
+	UserID, RemoteAddr, SegmentID string
+}
+
+func (f *fakeService) TrackSegmentFetch(_ context.Context, userID, remoteAddr, segmentID string) {
+	f.serve(segmentFetch{userID, remoteAddr, segmentID}, nil)
+}
+
+func (f *fakeService) GetRecentlyPlayed(_ context.Context, req structs.RecentlyPlayedReq) (resp structs.RecentlyPlayedResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetTopTracks(_ context.Context, req structs.TopReq) (resp structs.TopTracksResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetTopArtists(_ context.Context, req structs.TopReq) (resp structs.TopArtistsResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetCharts(_ context.Context, req structs.ChartsReq) (resp structs.ChartsResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) LikeSong(_ context.Context, req structs.LikeSongReq) (resp structs.LikeSongResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) SaveAlbum(_ context.Context, req structs.SaveAlbumReq) (resp structs.SaveAlbumResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetLikedSongs(_ context.Context, req structs.LibraryPageReq) (resp structs.GetLikedSongsResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetSavedAlbums(_ context.Context, req structs.LibraryPageReq) (resp structs.GetSavedAlbumsResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) AreSongsLiked(_ context.Context, req structs.AreSongsLikedReq) (resp structs.AreSongsLikedResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) GetRadio(_ context.Context, req structs.RadioReq) (resp structs.RadioResp, err error) {
+	f.serve(req, &resp)
+	return
+}
+
+func (f *fakeService) DownstreamStates(context.Context) (states map[string]string) {
+	f.serve(nil, &states)
+	return states
+}
+
+func (f *fakeService) CheckReadiness(context.Context) (resp structs.ReadinessResp) {
+	f.serve(nil, &resp)
+	return resp
+}
+
+func (f *fakeService) Close(context.Context) error { return nil }
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/playlistfmt"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
+	var req structs.UpdatePlaylistReq
+	var resp structs.UpdatePlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.UpdatePlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got UpdatePlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) MoveSongInPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req structs.MoveSongInPlaylistReq
+	var resp structs.MoveSongInPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.MoveSongInPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got MoveSongInPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) AddSongsToPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req structs.AddSongsToPlaylistReq
+	var resp structs.AddSongsToPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.AddSongsToPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got AddSongsToPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) RemoveSongsFromPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req structs.RemoveSongsFromPlaylistReq
+	var resp structs.RemoveSongsFromPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.RemoveSongsFromPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got RemoveSongsFromPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) SetPlaylistVisibility(w http.ResponseWriter, r *http.Request) {
+	var req structs.SetPlaylistVisibilityReq
+	var resp structs.SetPlaylistVisibilityResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.SetPlaylistVisibility(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got SetPlaylistVisibility() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) SharePlaylist(w http.ResponseWriter, r *http.Request) {
+	var req structs.SharePlaylistReq
+	var resp structs.SharePlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.SharePlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got SharePlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) FollowPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req structs.FollowPlaylistReq
+	var resp structs.FollowPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.FollowPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got FollowPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetPlaylistHistory(w http.ResponseWriter, r *http.Request) {
+	var req structs.GetPlaylistHistoryReq
+	var resp structs.GetPlaylistHistoryResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.GetPlaylistHistory(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetPlaylistHistory() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) RestorePlaylistVersion(w http.ResponseWriter, r *http.Request) {
+	var req structs.RestorePlaylistVersionReq
+	var resp structs.RestorePlaylistVersionResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.RestorePlaylistVersion(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got RestorePlaylistVersion() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetPlaylistStream(w http.ResponseWriter, r *http.Request) {
+	req := structsDB.GetPlaylistReq{
+		PlaylistID: r.URL.Query().Get("playlist_id"),
+		UserID:     r.URL.Query().Get("user_id"),
+	}
+
+	data, err := h.s.GetPlaylistStream(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetPlaylistStream() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	// playlist may change any time
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func (h *Handlers) ExportPlaylist(w http.ResponseWriter, r *http.Request) {
+	req := structs.ExportPlaylistReq{
+		UserID:     r.URL.Query().Get("user_id"),
+		PlaylistID: r.URL.Query().Get("playlist_id"),
+		Format:     r.URL.Query().Get("format"),
+	}
+
+	data, err := h.s.ExportPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got ExportPlaylist() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = playlistfmt.FormatM3U
+	}
+	w.Header().Set("Content-Type", playlistfmt.ContentType(req.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, req.PlaylistID, req.Format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func (h *Handlers) ImportPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req structs.ImportPlaylistReq
+	var resp structs.ImportPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.ImportPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got ImportPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
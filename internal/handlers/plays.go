@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) RecordPlayEvent(w http.ResponseWriter, r *http.Request) {
+	var req structs.PlayEventReq
+	var resp structs.PlayEventResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.RecordPlayEvent(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got RecordPlayEvent() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetRecentlyPlayed(w http.ResponseWriter, r *http.Request) {
+	var resp structs.RecentlyPlayedResp
+	req := structs.RecentlyPlayedReq{UserID: r.URL.Query().Get("user_id")}
+	if !parseLimit(w, r, &req.Limit) {
+		return
+	}
+
+	resp, err := h.s.GetRecentlyPlayed(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetRecentlyPlayed() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetTopTracks(w http.ResponseWriter, r *http.Request) {
+	var resp structs.TopTracksResp
+	req := structs.TopReq{UserID: r.URL.Query().Get("user_id"), Range: r.URL.Query().Get("range")}
+	if !parseLimit(w, r, &req.Limit) {
+		return
+	}
+
+	resp, err := h.s.GetTopTracks(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetTopTracks() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetTopArtists(w http.ResponseWriter, r *http.Request) {
+	var resp structs.TopArtistsResp
+	req := structs.TopReq{UserID: r.URL.Query().Get("user_id"), Range: r.URL.Query().Get("range")}
+	if !parseLimit(w, r, &req.Limit) {
+		return
+	}
+
+	resp, err := h.s.GetTopArtists(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetTopArtists() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetCharts(w http.ResponseWriter, r *http.Request) {
+	var resp structs.ChartsResp
+	req := structs.ChartsReq{Window: r.URL.Query().Get("window")}
+	if !parseLimit(w, r, &req.Limit) {
+		return
+	}
+
+	resp, err := h.s.GetCharts(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetCharts() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+// parseLimit reads optional limit query param, on error response is already sent
+func parseLimit(w http.ResponseWriter, r *http.Request, limit *int) bool {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		sendError(w, apperr.New(apperr.Validation, "invalid limit"))
+		return false
+	}
+	*limit = n
+	return true
+}
+
+func (h *Handlers) GetRadio(w http.ResponseWriter, r *http.Request) {
+	var resp structs.RadioResp
+	query := r.URL.Query()
+	req := structs.RadioReq{
+		UserID:     query.Get("user_id"),
+		SongID:     query.Get("song_id"),
+		Artist:     query.Get("artist"),
+		PlaylistID: query.Get("playlist_id"),
+	}
+	if !parseLimit(w, r, &req.Limit) {
+		return
+	}
+
+	resp, err := h.s.GetRadio(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetRadio() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/router"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	"go.uber.org/zap"
+)
+
+// initV2 registers resource oriented api, it uses the same service methods as legacy routes.
+// Ids of resources are taken from path, reads take user id from query.
+func (h *Handlers) initV2() {
+	h.handle(http.MethodGet, "/api/v2/songs", h.getSongs, apiDoc{
+		Summary: "Page of songs catalog",
+		Query:   songsPageParams,
+		Resp:    structs.GetSongsResp{},
+	})
+	h.handle(http.MethodPost, "/api/v2/songs", h.CreateSongV2, apiDoc{Summary: "Upload song", Body: structs.CreateNewSongReq{}, Resp: structs.CreateSongResp{}, Status: http.StatusCreated})
+	h.handle(http.MethodGet, "/api/v2/songs/{id}", h.GetSongV2, apiDoc{Summary: "Song", Resp: structs.GetSongResp{}})
+	h.handle(http.MethodPatch, "/api/v2/songs/{id}", h.UpdateSongV2, apiDoc{Summary: "Update sent fields of song metadata", Body: structs.PatchSongReq{}, Resp: structs.UpdateSongResp{}})
+	h.handle(http.MethodDelete, "/api/v2/songs/{id}", h.DeleteSongV2, apiDoc{Summary: "Delete song", Resp: structs.DeleteSongResp{}})
+
+	h.handle(http.MethodGet, "/api/v2/playlists", h.ListPlaylistsV2, apiDoc{Summary: "Playlists of user", Query: []string{"user_id"}, Resp: structsDB.GetUserAllPlaylistsResp{}})
+	h.handle(http.MethodPost, "/api/v2/playlists", h.CreatePlaylistV2, apiDoc{Summary: "Create playlist", Body: structs.CreatePlaylistReq{}, Resp: structsDB.NewPlaylistResp{}, Status: http.StatusCreated})
+	h.handle(http.MethodGet, "/api/v2/playlists/{id}", h.GetPlaylistV2, apiDoc{Summary: "Playlist", Query: []string{"user_id"}, Resp: structsDB.GetPlaylistResp{}})
+	h.handle(http.MethodPatch, "/api/v2/playlists/{id}", h.UpdatePlaylistV2, apiDoc{Summary: "Update playlist details", Body: structs.UpdatePlaylistReq{}, Resp: structs.UpdatePlaylistResp{}})
+	h.handle(http.MethodDelete, "/api/v2/playlists/{id}", h.DeletePlaylistV2, apiDoc{Summary: "Delete playlist", Query: []string{"user_id"}, Resp: structsDB.DeleteUserPlaylistResp{}})
+
+	h.handle(http.MethodGet, "/api/v2/playlists/{id}/tracks", h.GetPlaylistTracksV2, apiDoc{Summary: "Songs of playlist", Query: []string{"user_id"}, Resp: structs.GetPlaylistTracksResp{}})
+	h.handle(http.MethodPost, "/api/v2/playlists/{id}/tracks", h.AddPlaylistTracksV2, apiDoc{Summary: "Add songs to playlist", Body: structs.AddSongsToPlaylistReq{}, Resp: structs.AddSongsToPlaylistResp{}})
+	h.handle(http.MethodPatch, "/api/v2/playlists/{id}/tracks/{song_id}", h.MovePlaylistTrackV2, apiDoc{Summary: "Move song to position in playlist", Body: structs.MoveSongInPlaylistReq{}, Resp: structs.MoveSongInPlaylistResp{}})
+	h.handle(http.MethodDelete, "/api/v2/playlists/{id}/tracks/{song_id}", h.RemovePlaylistTrackV2, apiDoc{Summary: "Remove song from playlist", Query: []string{"user_id"}, Resp: structsDB.RemoveSongFromUserPlaylistResp{}})
+}
+
+func (h *Handlers) CreateSongV2(w http.ResponseWriter, r *http.Request) {
+	var req structs.CreateNewSongReq
+	var resp structs.CreateSongResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp.Song, err = h.s.CreateNewSong(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got CreateNewSong() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v2/songs/"+resp.Song.ID)
+	utils.SendJson(w, resp, http.StatusCreated)
+}
+
+func (h *Handlers) GetSongV2(w http.ResponseWriter, r *http.Request) {
+	req := structs.GetSongReq{ID: router.Param(r, "id")}
+	resp, err := h.s.GetSong(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetSong() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) UpdateSongV2(w http.ResponseWriter, r *http.Request) {
+	var req structs.PatchSongReq
+	var resp structs.UpdateSongResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	req.ID = router.Param(r, "id")
+
+	resp, err = h.s.PatchSong(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got PatchSong() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) DeleteSongV2(w http.ResponseWriter, r *http.Request) {
+	req := structs.DeleteSongReq{ID: router.Param(r, "id")}
+	resp, err := h.s.DeleteSong(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got DeleteSong() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) ListPlaylistsV2(w http.ResponseWriter, r *http.Request) {
+	req := structsDB.GetUserAllPlaylistsReq{UserID: r.URL.Query().Get("user_id")}
+	resp, err := h.s.GetUserPlaylists(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetUserPlaylists() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) CreatePlaylistV2(w http.ResponseWriter, r *http.Request) {
+	var req structs.CreatePlaylistReq
+	var resp structsDB.NewPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+
+	resp, err = h.s.NewPlaylist(r.Context(), structsDB.NewPlaylistReq{UserID: req.UserID, PlaylistName: req.Name})
+	if err != nil {
+		h.log(r).Error("got NewPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v2/playlists/"+resp.Playlist.ID)
+	utils.SendJson(w, resp, http.StatusCreated)
+}
+
+func (h *Handlers) GetPlaylistV2(w http.ResponseWriter, r *http.Request) {
+	req := structsDB.GetPlaylistReq{PlaylistID: router.Param(r, "id"), UserID: r.URL.Query().Get("user_id")}
+	resp, err := h.s.GetPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetPlaylist() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) UpdatePlaylistV2(w http.ResponseWriter, r *http.Request) {
+	var req structs.UpdatePlaylistReq
+	var resp structs.UpdatePlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	req.PlaylistID = router.Param(r, "id")
+
+	resp, err = h.s.UpdatePlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got UpdatePlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) DeletePlaylistV2(w http.ResponseWriter, r *http.Request) {
+	req := structsDB.DeleteUserPlaylistReq{PlaylistID: router.Param(r, "id"), UserID: r.URL.Query().Get("user_id")}
+	resp, err := h.s.DeletePlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got DeletePlaylist() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) GetPlaylistTracksV2(w http.ResponseWriter, r *http.Request) {
+	req := structsDB.GetPlaylistReq{PlaylistID: router.Param(r, "id"), UserID: r.URL.Query().Get("user_id")}
+	resp, err := h.s.GetPlaylistTracks(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got GetPlaylistTracks() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) AddPlaylistTracksV2(w http.ResponseWriter, r *http.Request) {
+	var req structs.AddSongsToPlaylistReq
+	var resp structs.AddSongsToPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	req.PlaylistID = router.Param(r, "id")
+
+	resp, err = h.s.AddSongsToPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got AddSongsToPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) MovePlaylistTrackV2(w http.ResponseWriter, r *http.Request) {
+	var req structs.MoveSongInPlaylistReq
+	var resp structs.MoveSongInPlaylistResp
+	err := utils.ParseJson(r, &req)
+	if err != nil {
+		h.log(r).Error("error reading body", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	req.PlaylistID = router.Param(r, "id")
+	req.SongID = router.Param(r, "song_id")
+
+	resp, err = h.s.MoveSongInPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got MoveSongInPlaylist() error", zap.Error(err))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) RemovePlaylistTrackV2(w http.ResponseWriter, r *http.Request) {
+	req := structsDB.RemoveSongFromUserPlaylistReq{
+		PlaylistID: router.Param(r, "id"),
+		SongID:     router.Param(r, "song_id"),
+		UserID:     r.URL.Query().Get("user_id"),
+	}
+	resp, err := h.s.RemoveSongFromPlaylist(r.Context(), req)
+	if err != nil {
+		h.log(r).Error("got RemoveSongFromPlaylist() error", zap.Error(err), zap.Any("req", req))
+		sendError(w, err)
+		return
+	}
+	utils.SendJson(w, resp, http.StatusOK)
+}
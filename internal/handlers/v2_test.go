@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	"go.uber.org/zap"
+)
+
+// TestV2Routes checks v2 routes and their legacy counterparts reach the same service method with the same request
+func TestV2Routes(t *testing.T) {
+	name, description := "Road trip", "Summer"
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		// legacy is route getting want as json body, empty when there is no legacy route
+		legacy   string
+		wantCall string
+		want     interface{}
+	}{
+		{
+			name:     "get song",
+			method:   http.MethodGet,
+			target:   "/api/v2/songs/s1",
+			wantCall: "GetSong",
+			want:     structs.GetSongReq{ID: "s1"},
+		},
+		{
+			// id from path wins over id in body
+			name:     "patch song",
+			method:   http.MethodPatch,
+			target:   "/api/v2/songs/s1",
+			body:     `{"id":"s2","name":"Road trip"}`,
+			wantCall: "PatchSong",
+			want:     structs.PatchSongReq{ID: "s1", Name: &name},
+		},
+		{
+			name:     "delete song",
+			method:   http.MethodDelete,
+			target:   "/api/v2/songs/s1",
+			legacy:   "/api/v1/delete_song",
+			wantCall: "DeleteSong",
+			want:     structs.DeleteSongReq{ID: "s1"},
+		},
+		{
+			name:     "list playlists",
+			method:   http.MethodGet,
+			target:   "/api/v2/playlists?user_id=u1",
+			legacy:   "/all_user_playlists",
+			wantCall: "GetUserPlaylists",
+			want:     structsDB.GetUserAllPlaylistsReq{UserID: "u1"},
+		},
+		{
+			name:     "create playlist",
+			method:   http.MethodPost,
+			target:   "/api/v2/playlists",
+			body:     `{"user_id":"u1","name":"Road trip"}`,
+			legacy:   "/new_playlist",
+			wantCall: "NewPlaylist",
+			want:     structsDB.NewPlaylistReq{UserID: "u1", PlaylistName: name},
+		},
+		{
+			name:     "get playlist",
+			method:   http.MethodGet,
+			target:   "/api/v2/playlists/p1?user_id=u1",
+			legacy:   "/get_playlist",
+			wantCall: "GetPlaylist",
+			want:     structsDB.GetPlaylistReq{PlaylistID: "p1", UserID: "u1"},
+		},
+		{
+			name:     "update playlist",
+			method:   http.MethodPatch,
+			target:   "/api/v2/playlists/p1",
+			body:     `{"user_id":"u1","playlist_id":"p2","name":"Road trip","description":"Summer"}`,
+			legacy:   "/update_playlist",
+			wantCall: "UpdatePlaylist",
+			want:     structs.UpdatePlaylistReq{UserID: "u1", PlaylistID: "p1", Name: name, Description: &description},
+		},
+		{
+			name:     "delete playlist",
+			method:   http.MethodDelete,
+			target:   "/api/v2/playlists/p1?user_id=u1",
+			legacy:   "/delete_playlist",
+			wantCall: "DeletePlaylist",
+			want:     structsDB.DeleteUserPlaylistReq{PlaylistID: "p1", UserID: "u1"},
+		},
+		{
+			name:     "playlist tracks",
+			method:   http.MethodGet,
+			target:   "/api/v2/playlists/p1/tracks?user_id=u1",
+			wantCall: "GetPlaylistTracks",
+			want:     structsDB.GetPlaylistReq{PlaylistID: "p1", UserID: "u1"},
+		},
+		{
+			name:     "add tracks",
+			method:   http.MethodPost,
+			target:   "/api/v2/playlists/p1/tracks",
+			body:     `{"user_id":"u1","song_ids":["s1","s2"]}`,
+			legacy:   "/add_songs_to_playlist",
+			wantCall: "AddSongsToPlaylist",
+			want:     structs.AddSongsToPlaylistReq{UserID: "u1", PlaylistID: "p1", SongIDs: []string{"s1", "s2"}},
+		},
+		{
+			name:     "move track",
+			method:   http.MethodPatch,
+			target:   "/api/v2/playlists/p1/tracks/s1",
+			body:     `{"user_id":"u1","position":2}`,
+			legacy:   "/move_song_in_playlist",
+			wantCall: "MoveSongInPlaylist",
+			want:     structs.MoveSongInPlaylistReq{UserID: "u1", PlaylistID: "p1", SongID: "s1", Position: 2},
+		},
+		{
+			name:     "remove track",
+			method:   http.MethodDelete,
+			target:   "/api/v2/playlists/p1/tracks/s1?user_id=u1",
+			legacy:   "/remove_song_from_playlist",
+			wantCall: "RemoveSongFromPlaylist",
+			want:     structsDB.RemoveSongFromUserPlaylistReq{PlaylistID: "p1", SongID: "s1", UserID: "u1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeService{}
+			h := NewHandlers(zap.NewNop(), fake)
+			h.InitHandlers()
+			handler := h.Handler()
+
+			check := func(method, target string, body []byte) {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(method, target, bytes.NewReader(body)))
+				if rec.Code >= http.StatusBadRequest {
+					t.Fatalf("%s %s: status %d, body %s", method, target, rec.Code, rec.Body)
+				}
+				if len(fake.calls) != 1 || fake.calls[0] != tt.wantCall {
+					t.Errorf("%s %s: called %v, want %s", method, target, fake.calls, tt.wantCall)
+				}
+				if len(fake.got) != 1 || !reflect.DeepEqual(fake.got[0], tt.want) {
+					t.Errorf("%s %s: got %+v, want %+v", method, target, fake.got, tt.want)
+				}
+				fake.got, fake.calls = nil, nil
+			}
+
+			check(tt.method, tt.target, []byte(tt.body))
+			if tt.legacy != "" {
+				body, err := json.Marshal(tt.want)
+				if err != nil {
+					t.Fatal(err)
+				}
+				check(http.MethodPost, tt.legacy, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Segment is one media segment of m3u8 playlist
+type Segment struct {
+	Duration float64
+	Title    string
+	URI      string
+}
+
+// MediaPlaylist is parsed m3u8 media playlist, only tags needed to join playlists are kept
+type MediaPlaylist struct {
+	TargetDuration int
+	Segments       []Segment
+}
+
+// Parse reads m3u8 media playlist as written by ffmpeg segment muxer
+func Parse(data []byte) (MediaPlaylist, error) {
+	var playlist MediaPlaylist
+	var current *Segment
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if first {
+			if line != "#EXTM3U" {
+				return playlist, errors.New("m3u8 should start with #EXTM3U")
+			}
+			first = false
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+			duration, err := strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-TARGETDURATION:"))
+			if err != nil {
+				return playlist, fmt.Errorf("bad target duration: %w", err)
+			}
+			playlist.TargetDuration = duration
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.SplitN(strings.TrimPrefix(line, "#EXTINF:"), ",", 2)
+			duration, err := strconv.ParseFloat(info[0], 64)
+			if err != nil {
+				return playlist, fmt.Errorf("bad segment duration: %w", err)
+			}
+			current = &Segment{Duration: duration}
+			if len(info) == 2 {
+				current.Title = info[1]
+			}
+		case strings.HasPrefix(line, "#"):
+			// other tags are not needed to join playlists
+		default:
+			if current == nil {
+				return playlist, fmt.Errorf("segment %s has no #EXTINF", line)
+			}
+			current.URI = line
+			playlist.Segments = append(playlist.Segments, *current)
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return playlist, err
+	}
+	if first {
+		return playlist, errors.New("m3u8 is empty")
+	}
+
+	return playlist, nil
+}
+
+// Join writes one VOD media playlist playing all tracks one after another,
+// tracks are separated by #EXT-X-DISCONTINUITY. Segment URIs are replaced
+// with their base name between uriPrefix and uriSuffix.
+func Join(tracks []MediaPlaylist, uriPrefix, uriSuffix string) []byte {
+	targetDuration := 0
+	for _, track := range tracks {
+		if track.TargetDuration > targetDuration {
+			targetDuration = track.TargetDuration
+		}
+		for _, segment := range track.Segments {
+			if d := int(math.Ceil(segment.Duration)); d > targetDuration {
+				targetDuration = d
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+	buf.WriteString("#EXT-X-VERSION:3\n")
+	buf.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	fmt.Fprintf(&buf, "#EXT-X-TARGETDURATION:%d\n", targetDuration)
+	buf.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	for i, track := range tracks {
+		if i != 0 {
+			buf.WriteString("#EXT-X-DISCONTINUITY\n")
+		}
+		for _, segment := range track.Segments {
+			fmt.Fprintf(&buf, "#EXTINF:%s,%s\n", strconv.FormatFloat(segment.Duration, 'f', 6, 64), segment.Title)
+			buf.WriteString(uriPrefix + path.Base(segment.URI) + uriSuffix + "\n")
+		}
+	}
+	buf.WriteString("#EXT-X-ENDLIST\n")
+	return buf.Bytes()
+}
+
+// AddURIQuery appends query to URI of every segment, other lines are kept as they are
+func AddURIQuery(data []byte, query string) []byte {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			separator := "?"
+			if strings.Contains(trimmed, "?") {
+				separator = "&"
+			}
+			line = trimmed + separator + query
+		}
+		buf.WriteString(line + "\n")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,154 @@
+package hls
+
+import (
+	"reflect"
+	"testing"
+)
+
+const ffmpegPlaylist = `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-MEDIA-SEQUENCE:0
+#EXT-X-ALLOW-CACHE:YES
+#EXT-X-TARGETDURATION:11
+#EXTINF:10.005333,
+song0.ts
+#EXTINF:4.5,intro
+song1.ts
+#EXT-X-ENDLIST
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    MediaPlaylist
+		wantErr bool
+	}{
+		{
+			name: "ffmpeg playlist",
+			data: ffmpegPlaylist,
+			want: MediaPlaylist{
+				TargetDuration: 11,
+				Segments: []Segment{
+					{Duration: 10.005333, URI: "song0.ts"},
+					{Duration: 4.5, Title: "intro", URI: "song1.ts"},
+				},
+			},
+		},
+		{
+			name: "blank lines and spaces",
+			data: "\n  #EXTM3U\n\n#EXTINF:2,\n  a.ts  \n",
+			want: MediaPlaylist{Segments: []Segment{{Duration: 2, URI: "a.ts"}}},
+		},
+		{name: "empty", data: "\n\n", wantErr: true},
+		{name: "no header", data: "#EXTINF:2,\na.ts\n", wantErr: true},
+		{name: "bad target duration", data: "#EXTM3U\n#EXT-X-TARGETDURATION:ten\n", wantErr: true},
+		{name: "bad segment duration", data: "#EXTM3U\n#EXTINF:x,\na.ts\n", wantErr: true},
+		{name: "segment without extinf", data: "#EXTM3U\na.ts\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	first := MediaPlaylist{
+		TargetDuration: 10,
+		Segments: []Segment{
+			{Duration: 10, URI: "songs/a/a0.ts"},
+			{Duration: 3.25, Title: "end", URI: "a1.ts"},
+		},
+	}
+	second := MediaPlaylist{
+		TargetDuration: 4,
+		Segments:       []Segment{{Duration: 12.2, URI: "b0.ts"}},
+	}
+
+	tests := []struct {
+		name   string
+		tracks []MediaPlaylist
+		prefix string
+		suffix string
+		want   string
+	}{
+		{
+			name:   "no tracks",
+			tracks: nil,
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-TARGETDURATION:0\n" +
+				"#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-ENDLIST\n",
+		},
+		{
+			name:   "one track",
+			tracks: []MediaPlaylist{first},
+			prefix: "/segments/",
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-TARGETDURATION:10\n" +
+				"#EXT-X-MEDIA-SEQUENCE:0\n" +
+				"#EXTINF:10.000000,\n/segments/a0.ts\n" +
+				"#EXTINF:3.250000,end\n/segments/a1.ts\n" +
+				"#EXT-X-ENDLIST\n",
+		},
+		{
+			// target duration is raised to the longest segment rounded up
+			name:   "tracks separated by discontinuity",
+			tracks: []MediaPlaylist{first, second},
+			prefix: "/segments/",
+			suffix: "?user_id=u1",
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-TARGETDURATION:13\n" +
+				"#EXT-X-MEDIA-SEQUENCE:0\n" +
+				"#EXTINF:10.000000,\n/segments/a0.ts?user_id=u1\n" +
+				"#EXTINF:3.250000,end\n/segments/a1.ts?user_id=u1\n" +
+				"#EXT-X-DISCONTINUITY\n" +
+				"#EXTINF:12.200000,\n/segments/b0.ts?user_id=u1\n" +
+				"#EXT-X-ENDLIST\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Join(tt.tracks, tt.prefix, tt.suffix)
+			if string(got) != tt.want {
+				t.Errorf("Join() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if _, err := Parse(got); err != nil {
+				t.Errorf("joined playlist does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddURIQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		query string
+		want  string
+	}{
+		{"empty", "", "user_id=u1", ""},
+		{
+			name:  "segments get query",
+			data:  "#EXTM3U\n#EXTINF:10,\nsong0.ts\n\n#EXT-X-ENDLIST\n",
+			query: "user_id=u1",
+			want:  "#EXTM3U\n#EXTINF:10,\nsong0.ts?user_id=u1\n\n#EXT-X-ENDLIST\n",
+		},
+		{
+			name:  "uri with query",
+			data:  "#EXTINF:10,\n song0.ts?v=2 \n",
+			query: "user_id=u1",
+			want:  "#EXTINF:10,\nsong0.ts?v=2&user_id=u1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddURIQuery([]byte(tt.data), tt.query); string(got) != tt.want {
+				t.Errorf("AddURIQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
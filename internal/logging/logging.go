@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader is header request id is taken from and passed to db and auth in
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen limits request id sent by client, longer ids are replaced
+const maxRequestIDLen = 128
+
+type contextKey struct{}
+
+// request is state of one http request shared by middleware, handlers and service
+type request struct {
+	id     string
+	logger *zap.Logger
+	userID string
+}
+
+// NewContext returns ctx carrying request id and logger of the request
+func NewContext(ctx context.Context, requestID string, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, &request{id: requestID, logger: logger})
+}
+
+func fromContext(ctx context.Context) *request {
+	req, _ := ctx.Value(contextKey{}).(*request)
+	return req
+}
+
+// Logger returns logger of request, fallback is used outside of requests
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if req := fromContext(ctx); req != nil {
+		return req.logger
+	}
+	return fallback
+}
+
+// RequestID returns id of request or empty string outside of requests
+func RequestID(ctx context.Context) string {
+	if req := fromContext(ctx); req != nil {
+		return req.id
+	}
+	return ""
+}
+
+// SetUserID remembers user making request for access log
+func SetUserID(ctx context.Context, userID string) {
+	if req := fromContext(ctx); req != nil && userID != "" {
+		req.userID = userID
+	}
+}
+
+// UserID returns user set by SetUserID
+func UserID(ctx context.Context) string {
+	if req := fromContext(ctx); req != nil {
+		return req.userID
+	}
+	return ""
+}
+
+// NewRequestID returns id sent by client if it looks sane, otherwise new random id
+func NewRequestID(sent string) string {
+	if validRequestID(sent) {
+		return sent
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// validRequestID allows only printable ascii, id is written to logs and headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
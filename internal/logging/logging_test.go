@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNewRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		sent     string
+		wantSent bool
+	}{
+		{"sent by client", "abc-123", true},
+		{"longest allowed", strings.Repeat("a", maxRequestIDLen), true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("a", maxRequestIDLen+1), false},
+		{"with space", "abc 123", false},
+		// new line would let client forge log lines
+		{"with new line", "abc\n123", false},
+		{"not ascii", "абв", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRequestID(tt.sent)
+			if tt.wantSent {
+				if got != tt.sent {
+					t.Errorf("NewRequestID() = %q, want sent id", got)
+				}
+				return
+			}
+			if got == tt.sent || len(got) != 32 {
+				t.Errorf("NewRequestID() = %q, want new random id", got)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	fallback, logger := zap.NewNop(), zap.NewExample()
+
+	ctx := context.Background()
+	SetUserID(ctx, "u1")
+	if RequestID(ctx) != "" || UserID(ctx) != "" || Logger(ctx, fallback) != fallback {
+		t.Error("context outside of request has request state")
+	}
+
+	ctx = NewContext(ctx, "r1", logger)
+	SetUserID(ctx, "u1")
+	// empty id does not reset user found before
+	SetUserID(ctx, "")
+	if RequestID(ctx) != "r1" || UserID(ctx) != "u1" || Logger(ctx, fallback) != logger {
+		t.Errorf("request state = %q, %q, want r1, u1 and request logger", RequestID(ctx), UserID(ctx))
+	}
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "spotify_back"
+
+// cache names used in CacheRequests
+const (
+	CacheCatalog = "catalog"
+	CacheArtwork = "artwork"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Handled http requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of handled http requests by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	DownstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "downstream_request_duration_seconds",
+		Help:      "Latency of calls to db and auth by host, path and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "path", "result"})
+
+	TranscodeDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transcode_duration_seconds",
+		Help:      "Duration of mp3 to hls transcoding.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 10),
+	})
+
+	TranscodeFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transcode_failures_total",
+		Help:      "Failed mp3 to hls transcodings.",
+	})
+
+	SegmentBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "segment_bytes_served_total",
+		Help:      "Bytes of m3u8 and ts segments sent to clients.",
+	})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache and result, hit ratio is hit / (hit + miss).",
+	}, []string{"cache", "result"})
+)
+
+// CacheLookup counts hit or miss of cache
+func CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheRequests.WithLabelValues(cache, result).Inc()
+}
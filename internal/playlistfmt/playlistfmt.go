@@ -0,0 +1,201 @@
+package playlistfmt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// supported formats, m3u8 is the same as m3u and is always written in utf-8
+const (
+	FormatM3U  = "m3u"
+	FormatM3U8 = "m3u8"
+	FormatXSPF = "xspf"
+	FormatJSON = "json"
+)
+
+// Entry is one track of exported or imported playlist
+type Entry struct {
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	Location string `json:"location"`
+}
+
+// Playlist is format independent playlist, it is also the JSON export format
+type Playlist struct {
+	Name    string  `json:"name"`
+	Entries []Entry `json:"entries"`
+}
+
+// ContentType returns mime type of format
+func ContentType(format string) string {
+	switch format {
+	case FormatM3U, FormatM3U8:
+		return "audio/x-mpegurl"
+	case FormatXSPF:
+		return "application/xspf+xml"
+	default:
+		return "application/json"
+	}
+}
+
+func Encode(format string, playlist Playlist) ([]byte, error) {
+	switch format {
+	case FormatM3U, FormatM3U8:
+		return encodeM3U(playlist), nil
+	case FormatXSPF:
+		return encodeXSPF(playlist)
+	case FormatJSON:
+		return json.Marshal(playlist)
+	}
+	return nil, fmt.Errorf("unknown format %s", format)
+}
+
+// Decode parses playlist, empty format is detected from data
+func Decode(format string, data []byte) (Playlist, error) {
+	if format == "" {
+		format = detect(data)
+	}
+	switch format {
+	case FormatM3U, FormatM3U8:
+		return decodeM3U(data), nil
+	case FormatXSPF:
+		return decodeXSPF(data)
+	case FormatJSON:
+		var playlist Playlist
+		err := json.Unmarshal(data, &playlist)
+		return playlist, err
+	}
+	return Playlist{}, fmt.Errorf("unknown format %s", format)
+}
+
+func detect(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatJSON
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatXSPF
+	default:
+		return FormatM3U
+	}
+}
+
+func encodeM3U(playlist Playlist) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+	if playlist.Name != "" {
+		fmt.Fprintf(&buf, "#PLAYLIST:%s\n", playlist.Name)
+	}
+	for _, entry := range playlist.Entries {
+		fmt.Fprintf(&buf, "#EXTINF:-1,%s - %s\n", entry.Artist, entry.Title)
+		if entry.Album != "" {
+			fmt.Fprintf(&buf, "#EXTALB:%s\n", entry.Album)
+		}
+		buf.WriteString(entry.Location + "\n")
+	}
+	return buf.Bytes()
+}
+
+// decodeM3U reads plain and extended m3u, entries without #EXTINF get title from file name
+func decodeM3U(data []byte) Playlist {
+	var playlist Playlist
+	var current Entry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			playlist.Name = strings.TrimPrefix(line, "#PLAYLIST:")
+		case strings.HasPrefix(line, "#EXTALB:"):
+			current.Album = strings.TrimPrefix(line, "#EXTALB:")
+		case strings.HasPrefix(line, "#EXTART:"):
+			current.Artist = strings.TrimPrefix(line, "#EXTART:")
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.SplitN(line, ",", 2)
+			if len(info) == 2 {
+				current.Artist, current.Title = splitArtistTitle(info[1])
+			}
+		case strings.HasPrefix(line, "#"):
+		default:
+			current.Location = line
+			if current.Title == "" {
+				name := path.Base(strings.ReplaceAll(line, "\\", "/"))
+				current.Artist, current.Title = splitArtistTitle(strings.TrimSuffix(name, path.Ext(name)))
+			}
+			playlist.Entries = append(playlist.Entries, current)
+			current = Entry{}
+		}
+	}
+	return playlist
+}
+
+// splitArtistTitle splits "Artist - Title", string without separator is title
+func splitArtistTitle(s string) (string, string) {
+	parts := strings.SplitN(s, " - ", 2)
+	if len(parts) == 1 {
+		return "", strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version string      `xml:"version,attr"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location,omitempty"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Album    string `xml:"album,omitempty"`
+}
+
+func encodeXSPF(playlist Playlist) ([]byte, error) {
+	doc := xspfPlaylist{Version: "1", Title: playlist.Name}
+	for _, entry := range playlist.Entries {
+		doc.Tracks = append(doc.Tracks, xspfTrack{
+			Location: entry.Location,
+			Title:    entry.Title,
+			Creator:  entry.Artist,
+			Album:    entry.Album,
+		})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func decodeXSPF(data []byte) (Playlist, error) {
+	var doc xspfPlaylist
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return Playlist{}, err
+	}
+	if doc.XMLName.Local != "playlist" {
+		return Playlist{}, errors.New("xspf root should be playlist")
+	}
+
+	playlist := Playlist{Name: doc.Title}
+	for _, track := range doc.Tracks {
+		playlist.Entries = append(playlist.Entries, Entry{
+			Title:    track.Title,
+			Artist:   track.Creator,
+			Album:    track.Album,
+			Location: track.Location,
+		})
+	}
+	return playlist, nil
+}
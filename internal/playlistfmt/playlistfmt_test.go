@@ -0,0 +1,132 @@
+package playlistfmt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		want    Playlist
+		wantErr bool
+	}{
+		{
+			name:   "extended m3u",
+			format: FormatM3U,
+			data: "\ufeff#EXTM3U\n#PLAYLIST:Road trip\n" +
+				"#EXTINF:354,Queen - Bohemian Rhapsody\n#EXTALB:A Night at the Opera\n/music/queen/bohemian.mp3\n\n" +
+				"#EXTINF:-1,Intro\n#EXTART:Unknown\nintro.mp3\n",
+			want: Playlist{Name: "Road trip", Entries: []Entry{
+				{Title: "Bohemian Rhapsody", Artist: "Queen", Album: "A Night at the Opera", Location: "/music/queen/bohemian.mp3"},
+				{Title: "Intro", Artist: "Unknown", Location: "intro.mp3"},
+			}},
+		},
+		{
+			// title and artist come from file name
+			name:   "plain m3u",
+			format: FormatM3U8,
+			data:   "C:\\Music\\AC-DC - Back in Black.mp3\nhttp://example.com/stream/track.ogg\n",
+			want: Playlist{Entries: []Entry{
+				{Title: "Back in Black", Artist: "AC-DC", Location: "C:\\Music\\AC-DC - Back in Black.mp3"},
+				{Title: "track", Location: "http://example.com/stream/track.ogg"},
+			}},
+		},
+		{
+			name:   "xspf",
+			format: FormatXSPF,
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Mix</title>
+  <trackList>
+    <track><location>file:///a.mp3</location><title>Hells Bells</title><creator>AC/DC</creator><album>Back in Black</album></track>
+    <track><title>Yesterday</title></track>
+  </trackList>
+</playlist>`,
+			want: Playlist{Name: "Mix", Entries: []Entry{
+				{Title: "Hells Bells", Artist: "AC/DC", Album: "Back in Black", Location: "file:///a.mp3"},
+				{Title: "Yesterday"},
+			}},
+		},
+		{name: "xspf with other root", format: FormatXSPF, data: `<list xmlns="http://xspf.org/ns/0/"></list>`, wantErr: true},
+		{name: "broken xspf", format: FormatXSPF, data: `<playlist`, wantErr: true},
+		{
+			name:   "json",
+			format: FormatJSON,
+			data:   `{"name":"Mix","entries":[{"title":"Yesterday","artist":"The Beatles","album":"Help!","location":"y.mp3"}]}`,
+			want: Playlist{Name: "Mix", Entries: []Entry{
+				{Title: "Yesterday", Artist: "The Beatles", Album: "Help!", Location: "y.mp3"},
+			}},
+		},
+		{name: "broken json", format: FormatJSON, data: `{"name":`, wantErr: true},
+		{
+			name: "detect json",
+			data: "  \n{\"name\":\"Mix\"}",
+			want: Playlist{Name: "Mix"},
+		},
+		{
+			name: "detect xspf",
+			data: `<playlist version="1" xmlns="http://xspf.org/ns/0/"><title>Mix</title></playlist>`,
+			want: Playlist{Name: "Mix"},
+		},
+		{
+			name: "detect m3u",
+			data: "#EXTM3U\n#EXTINF:1,Yesterday\ny.mp3\n",
+			want: Playlist{Entries: []Entry{{Title: "Yesterday", Location: "y.mp3"}}},
+		},
+		{name: "unknown format", format: "pls", data: "[playlist]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.format, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	playlist := Playlist{Name: "Road trip", Entries: []Entry{
+		{Title: "Bohemian Rhapsody", Artist: "Queen", Album: "A Night at the Opera", Location: "https://example.com/songs/1.m3u8"},
+		{Title: "Rock & Roll", Artist: "Led Zeppelin", Location: "https://example.com/songs/2.m3u8"},
+	}}
+
+	tests := []struct {
+		format      string
+		contentType string
+	}{
+		{FormatM3U, "audio/x-mpegurl"},
+		{FormatM3U8, "audio/x-mpegurl"},
+		{FormatXSPF, "application/xspf+xml"},
+		{FormatJSON, "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			data, err := Encode(tt.format, playlist)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			// format is detected on import
+			got, err := Decode("", data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, playlist) {
+				t.Errorf("round trip = %+v, want %+v", got, playlist)
+			}
+			if got := ContentType(tt.format); got != tt.contentType {
+				t.Errorf("ContentType() = %q, want %q", got, tt.contentType)
+			}
+		})
+	}
+
+	if _, err := Encode("pls", playlist); err == nil {
+		t.Error("Encode() with unknown format has no error")
+	}
+}
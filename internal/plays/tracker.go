@@ -0,0 +1,117 @@
+package plays
+
+import (
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// SegmentDuration is length of ts segments made by example/create.sh
+	SegmentDuration = 10 * time.Second
+	// MinPlayDuration is how long song should be listened to be counted as play
+	MinPlayDuration = 30 * time.Second
+	// sessionTTL is inactivity after which listening the same song again is new play
+	sessionTTL = 10 * time.Minute
+)
+
+var segmentRe = regexp.MustCompile(`^(.+)_(\d{3,})\.ts$`)
+
+// SongIDFromSegment returns song id of ts segment id like "<song id>_000.ts"
+func SongIDFromSegment(segmentID string) (string, bool) {
+	match := segmentRe.FindStringSubmatch(segmentID)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+type sessionKey struct {
+	listener string
+	songID   string
+}
+
+type session struct {
+	segments  map[string]bool
+	firstSeen time.Time
+	lastSeen  time.Time
+	// done is set when play was inferred or reported by client
+	done bool
+}
+
+// Tracker infers plays from segment fetches. Listener fetching segments covering
+// MinPlayDuration of a song is counted as one play per listening session.
+// Players prefetch several segments at once, so fetches should also span
+// MinPlayDuration less the segment being played, skipped songs are not counted.
+type Tracker struct {
+	mu        sync.Mutex
+	sessions  map[sessionKey]*session
+	lastSweep time.Time
+	onPlay    func(listener, songID string)
+	now       func() time.Time
+}
+
+// NewTracker creates tracker calling onPlay for every inferred play
+func NewTracker(onPlay func(listener, songID string)) *Tracker {
+	return &Tracker{
+		sessions: make(map[sessionKey]*session),
+		onPlay:   onPlay,
+		now:      time.Now,
+	}
+}
+
+// SegmentFetched registers segment served to listener, other files are ignored
+func (t *Tracker) SegmentFetched(listener, segmentID string) {
+	songID, ok := SongIDFromSegment(segmentID)
+	if !ok || listener == "" {
+		return
+	}
+
+	t.mu.Lock()
+	now := t.now()
+	t.sweep(now)
+	s := t.session(sessionKey{listener, songID}, now)
+	s.segments[segmentID] = true
+	played := !s.done &&
+		time.Duration(len(s.segments))*SegmentDuration >= MinPlayDuration &&
+		s.lastSeen.Sub(s.firstSeen) >= MinPlayDuration-SegmentDuration
+	if played {
+		s.done = true
+	}
+	t.mu.Unlock()
+
+	if played {
+		t.onPlay(listener, songID)
+	}
+}
+
+// Reported marks current session as already counted, so plays reported by
+// client are not counted twice
+func (t *Tracker) Reported(listener, songID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.session(sessionKey{listener, songID}, t.now()).done = true
+}
+
+func (t *Tracker) session(key sessionKey, now time.Time) *session {
+	s, ok := t.sessions[key]
+	if !ok || now.Sub(s.lastSeen) > sessionTTL {
+		s = &session{segments: make(map[string]bool), firstSeen: now}
+		t.sessions[key] = s
+	}
+	s.lastSeen = now
+	return s
+}
+
+// sweep drops expired sessions, it runs at most once per sessionTTL
+func (t *Tracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < sessionTTL {
+		return
+	}
+	t.lastSweep = now
+	for key, s := range t.sessions {
+		if now.Sub(s.lastSeen) > sessionTTL {
+			delete(t.sessions, key)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package plays
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSongIDFromSegment(t *testing.T) {
+	tests := []struct {
+		segmentID string
+		want      string
+		wantOK    bool
+	}{
+		{"1650000000000_000.ts", "1650000000000", true},
+		{"song_with_underscores_1234.ts", "song_with_underscores", true},
+		{"1650000000000.m3u8", "", false},
+		{"1650000000000_00.ts", "", false},
+		{"_000.ts", "", false},
+		{"cover_300.jpg", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.segmentID, func(t *testing.T) {
+			got, ok := SongIDFromSegment(tt.segmentID)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("SongIDFromSegment() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTrackerSegmentFetched(t *testing.T) {
+	// fetch is segment served at time after start, reported marks client reported playback instead
+	type fetch struct {
+		at       time.Duration
+		listener string
+		segment  string
+		reported bool
+	}
+	listen := func(listener, song string, start time.Duration, segments int) []fetch {
+		var fetches []fetch
+		for i := 0; i < segments; i++ {
+			// player buffers three segments and then fetches one per segment played
+			at := start
+			if i >= 3 {
+				at += time.Duration(i-2) * SegmentDuration
+			}
+			fetches = append(fetches, fetch{at: at, listener: listener, segment: fmt.Sprintf("%s_%03d.ts", song, i)})
+		}
+		return fetches
+	}
+	join := func(parts ...[]fetch) []fetch {
+		var all []fetch
+		for _, part := range parts {
+			all = append(all, part...)
+		}
+		return all
+	}
+
+	tests := []struct {
+		name    string
+		fetches []fetch
+		want    []string
+	}{
+		{
+			// whole buffer is fetched in the first seconds
+			name:    "skipped after prefetch",
+			fetches: join(listen("u1", "s1", 0, 3), []fetch{{at: 2 * time.Second, listener: "u1", segment: "s1_003.ts"}}),
+		},
+		{
+			name:    "listened long enough",
+			fetches: listen("u1", "s1", 0, 5),
+			want:    []string{"u1/s1"},
+		},
+		{
+			name:    "stopped before min duration",
+			fetches: listen("u1", "s1", 0, 4),
+		},
+		{
+			name: "same segment refetched",
+			fetches: []fetch{
+				{at: 0, listener: "u1", segment: "s1_000.ts"},
+				{at: 20 * time.Second, listener: "u1", segment: "s1_000.ts"},
+				{at: 40 * time.Second, listener: "u1", segment: "s1_000.ts"},
+			},
+		},
+		{
+			name:    "one play per session",
+			fetches: listen("u1", "s1", 0, 12),
+			want:    []string{"u1/s1"},
+		},
+		{
+			name:    "listened again after session expired",
+			fetches: join(listen("u1", "s1", 0, 5), listen("u1", "s1", time.Hour, 5)),
+			want:    []string{"u1/s1", "u1/s1"},
+		},
+		{
+			// replay within session is still the same play
+			name:    "listened again within session",
+			fetches: join(listen("u1", "s1", 0, 5), listen("u1", "s1", 5*time.Minute, 5)),
+			want:    []string{"u1/s1"},
+		},
+		{
+			name:    "reported by client",
+			fetches: join([]fetch{{listener: "u1", segment: "s1_000.ts", reported: true}}, listen("u1", "s1", 0, 5)),
+		},
+		{
+			name:    "listeners and songs are separate",
+			fetches: join(listen("u1", "s1", 0, 5), listen("u2", "s1", 0, 5), listen("u1", "s2", 0, 4)),
+			want:    []string{"u1/s1", "u2/s1"},
+		},
+		{
+			name: "other files and anonymous fetches are ignored",
+			fetches: []fetch{
+				{at: 0, listener: "u1", segment: "s1.m3u8"},
+				{at: 30 * time.Second, listener: "u1", segment: "s1.m3u8"},
+				{at: 0, listener: "", segment: "s1_000.ts"},
+				{at: 30 * time.Second, listener: "", segment: "s1_001.ts"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			tracker := NewTracker(func(listener, songID string) {
+				got = append(got, listener+"/"+songID)
+			})
+			start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			for _, f := range tt.fetches {
+				tracker.now = func() time.Time { return start.Add(f.at) }
+				if f.reported {
+					songID, _ := SongIDFromSegment(f.segment)
+					tracker.Reported(f.listener, songID)
+					continue
+				}
+				tracker.SegmentFetched(f.listener, f.segment)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("plays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(func(string, string) {})
+	tracker.now = func() time.Time { return now }
+
+	tracker.SegmentFetched("u1", "s1_000.ts")
+	tracker.SegmentFetched("u2", "s1_000.ts")
+	now = now.Add(sessionTTL + time.Second)
+	tracker.SegmentFetched("u3", "s1_000.ts")
+
+	if len(tracker.sessions) != 1 {
+		t.Errorf("%d sessions kept, want only the active one", len(tracker.sessions))
+	}
+}
@@ -0,0 +1,132 @@
+package recommend
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// maxBasketSize caps songs taken from one playlist or listening session,
+	// pairs grow quadratically with basket size
+	maxBasketSize = 200
+	// topK similar songs are kept per song
+	topK = 100
+)
+
+// Scored is song with similarity score
+type Scored struct {
+	SongID string
+	Score  float64
+}
+
+// Engine serves similar songs computed by last Build
+type Engine struct {
+	mu      sync.RWMutex
+	similar map[string][]Scored
+}
+
+func NewEngine() *Engine {
+	return &Engine{similar: make(map[string][]Scored)}
+}
+
+// Build computes item to item cosine similarity of songs appearing together
+// in baskets, basket is playlist or songs listened by user in one session.
+// Result replaces previous one.
+func (e *Engine) Build(baskets [][]string) {
+	occurrences := make(map[string]int)
+	pairs := make(map[string]map[string]int)
+
+	for _, basket := range baskets {
+		songs := unique(basket)
+		for i, a := range songs {
+			occurrences[a]++
+			for _, b := range songs[i+1:] {
+				addPair(pairs, a, b)
+				addPair(pairs, b, a)
+			}
+		}
+	}
+
+	similar := make(map[string][]Scored, len(pairs))
+	for a, others := range pairs {
+		scored := make([]Scored, 0, len(others))
+		for b, together := range others {
+			score := float64(together) / math.Sqrt(float64(occurrences[a]*occurrences[b]))
+			scored = append(scored, Scored{SongID: b, Score: score})
+		}
+		sortScored(scored)
+		if len(scored) > topK {
+			scored = scored[:topK]
+		}
+		similar[a] = scored
+	}
+
+	e.mu.Lock()
+	e.similar = similar
+	e.mu.Unlock()
+}
+
+// Recommend returns songs similar to all seeds ordered by summed similarity, seeds are excluded
+func (e *Engine) Recommend(seeds []string, limit int) []Scored {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	isSeed := make(map[string]bool, len(seeds))
+	for _, seed := range seeds {
+		isSeed[seed] = true
+	}
+
+	scores := make(map[string]float64)
+	for _, seed := range seeds {
+		for _, s := range e.similar[seed] {
+			if !isSeed[s.SongID] {
+				scores[s.SongID] += s.Score
+			}
+		}
+	}
+
+	result := make([]Scored, 0, len(scores))
+	for id, score := range scores {
+		result = append(result, Scored{SongID: id, Score: score})
+	}
+	sortScored(result)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func addPair(pairs map[string]map[string]int, a, b string) {
+	others, ok := pairs[a]
+	if !ok {
+		others = make(map[string]int)
+		pairs[a] = others
+	}
+	others[b]++
+}
+
+func unique(basket []string) []string {
+	seen := make(map[string]bool, len(basket))
+	result := make([]string, 0, len(basket))
+	for _, id := range basket {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		if len(result) == maxBasketSize {
+			break
+		}
+	}
+	return result
+}
+
+func sortScored(scored []Scored) {
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].SongID < scored[j].SongID
+	})
+}
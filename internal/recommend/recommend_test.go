@@ -0,0 +1,74 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestEngineRecommend(t *testing.T) {
+	e := NewEngine()
+	e.Build([][]string{
+		{"a", "b", "c"},
+		{"a", "b"},
+		// repeated and empty ids are counted once
+		{"a", "b", "a", ""},
+		{"c", "d"},
+	})
+
+	tests := []struct {
+		name  string
+		seeds []string
+		limit int
+		want  []Scored
+	}{
+		{name: "always together", seeds: []string{"a"}, want: []Scored{{"b", 1}, {"c", 1 / math.Sqrt(6)}}},
+		{name: "scores are summed", seeds: []string{"a", "b"}, want: []Scored{{"c", 2 / math.Sqrt(6)}}},
+		{name: "ties by id", seeds: []string{"c"}, want: []Scored{{"d", 1 / math.Sqrt(2)}, {"a", 1 / math.Sqrt(6)}, {"b", 1 / math.Sqrt(6)}}},
+		{name: "limit", seeds: []string{"c"}, limit: 1, want: []Scored{{"d", 1 / math.Sqrt(2)}}},
+		{name: "unknown seed", seeds: []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := e.Recommend(tt.seeds, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Recommend() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].SongID != tt.want[i].SongID || math.Abs(got[i].Score-tt.want[i].Score) > 1e-9 {
+					t.Errorf("Recommend() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestEngineBuild(t *testing.T) {
+	long := make([]string, maxBasketSize+1)
+	for i := range long {
+		long[i] = fmt.Sprint(i)
+	}
+
+	tests := []struct {
+		name    string
+		baskets [][]string
+		seed    string
+		want    int
+	}{
+		{name: "song past basket cap", baskets: [][]string{long}, seed: fmt.Sprint(maxBasketSize)},
+		{name: "top similar songs are kept", baskets: [][]string{long}, seed: "0", want: topK},
+		{name: "single song basket", baskets: [][]string{{"a"}}, seed: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine()
+			// previous build is replaced
+			e.Build([][]string{{tt.seed, "old"}})
+			e.Build(tt.baskets)
+			if got := e.Recommend([]string{tt.seed}, 0); len(got) != tt.want {
+				t.Errorf("%d similar songs, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Route is method and pattern handler was registered with
+type Route struct {
+	Method  string
+	Pattern string
+}
+
+type route struct {
+	Route
+	segments []string
+	handler  http.Handler
+}
+
+// Router matches requests by method and path. Pattern segments in braces,
+// like /playlists/{id}, match any one path segment and are read with Param.
+// Static segments win over parameters, so /songs/top is matched before /songs/{id}.
+type Router struct {
+	routes []*route
+
+	// NotFound answers requests whose path matches no pattern
+	NotFound http.Handler
+	// MethodNotAllowed answers requests whose path matches only patterns of other
+	// methods, Allow header is already set when it is called
+	MethodNotAllowed http.Handler
+}
+
+func New() *Router {
+	return &Router{
+		NotFound: http.NotFoundHandler(),
+		MethodNotAllowed: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}),
+	}
+}
+
+// Handle registers handler, it panics on pattern registered twice for method
+func (rt *Router) Handle(method, pattern string, handler http.Handler) {
+	if !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("router: pattern %q should start with /", pattern))
+	}
+	segments := splitPath(pattern)
+	for _, existing := range rt.routes {
+		if existing.Method == method && sameSegments(existing.segments, segments) {
+			panic(fmt.Sprintf("router: %s %s conflicts with %s", method, pattern, existing.Pattern))
+		}
+	}
+	rt.routes = append(rt.routes, &route{
+		Route:    Route{Method: method, Pattern: pattern},
+		segments: segments,
+		handler:  handler,
+	})
+}
+
+func (rt *Router) HandleFunc(method, pattern string, handler http.HandlerFunc) {
+	rt.Handle(method, pattern, handler)
+}
+
+// Routes returns all registered routes in registration order
+func (rt *Router) Routes() []Route {
+	routes := make([]Route, len(rt.routes))
+	for i, r := range rt.routes {
+		routes[i] = r.Route
+	}
+	return routes
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := splitPath(r.URL.Path)
+
+	var best, get *route
+	var bestParams, getParams map[string]string
+	allowed := make(map[string]bool)
+	for _, candidate := range rt.routes {
+		params, ok := match(candidate.segments, path)
+		if !ok {
+			continue
+		}
+		allowed[candidate.Method] = true
+		if candidate.Method == r.Method && (best == nil || moreSpecific(candidate, best)) {
+			best, bestParams = candidate, params
+		}
+		// HEAD is answered by GET handler when there is no own one
+		if candidate.Method == http.MethodGet && (get == nil || moreSpecific(candidate, get)) {
+			get, getParams = candidate, params
+		}
+	}
+	if best == nil && r.Method == http.MethodHead && get != nil {
+		best, bestParams = get, getParams
+	}
+
+	switch {
+	case best != nil:
+		if len(bestParams) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), paramsKey{}, bestParams))
+		}
+		best.handler.ServeHTTP(w, r)
+	case len(allowed) > 0:
+		w.Header().Set("Allow", allowHeader(allowed))
+		rt.MethodNotAllowed.ServeHTTP(w, r)
+	default:
+		rt.NotFound.ServeHTTP(w, r)
+	}
+}
+
+type paramsKey struct{}
+
+// Param returns path parameter of request matched by Router
+func Param(r *http.Request, name string) string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params[name]
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+func paramName(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+func match(segments, path []string) (map[string]string, bool) {
+	if len(segments) != len(path) {
+		return nil, false
+	}
+	var params map[string]string
+	for i, segment := range segments {
+		if name, ok := paramName(segment); ok {
+			// parameter matches only non empty segment
+			if path[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = path[i]
+			continue
+		}
+		if segment != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// moreSpecific reports whether a has static segment earlier than b, both match the same path
+func moreSpecific(a, b *route) bool {
+	for i := range a.segments {
+		_, aParam := paramName(a.segments[i])
+		_, bParam := paramName(b.segments[i])
+		if aParam != bParam {
+			return bParam
+		}
+	}
+	return false
+}
+
+// sameSegments reports whether patterns match the same paths, parameter names aside
+func sameSegments(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		_, aParam := paramName(a[i])
+		_, bParam := paramName(b[i])
+		if aParam != bParam || (!aParam && a[i] != b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func allowHeader(allowed map[string]bool) string {
+	if allowed[http.MethodGet] {
+		allowed[http.MethodHead] = true
+	}
+	methods := make([]string, 0, len(allowed))
+	for method := range allowed {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
@@ -0,0 +1,112 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterServeHTTP(t *testing.T) {
+	rt := New()
+	for _, r := range []Route{
+		{http.MethodGet, "/songs"},
+		{http.MethodPost, "/songs"},
+		{http.MethodGet, "/songs/{id}"},
+		{http.MethodGet, "/songs/top"},
+		{http.MethodDelete, "/songs/{id}"},
+		{http.MethodHead, "/artwork/{name}"},
+		{http.MethodGet, "/artwork/{name}"},
+		{http.MethodPatch, "/playlists/{id}/tracks/{song_id}"},
+	} {
+		route := r
+		rt.HandleFunc(route.Method, route.Pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Route", route.Method+" "+route.Pattern)
+			w.Header().Set("X-Params", Param(r, "id")+","+Param(r, "song_id")+","+Param(r, "name"))
+		})
+	}
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+		wantRoute    string
+		wantParams   string
+		wantAllow    string
+	}{
+		{http.MethodGet, "/songs", http.StatusOK, "GET /songs", ",,", ""},
+		{http.MethodPost, "/songs", http.StatusOK, "POST /songs", ",,", ""},
+		{http.MethodGet, "/songs/42", http.StatusOK, "GET /songs/{id}", "42,,", ""},
+		// static segment wins over parameter
+		{http.MethodGet, "/songs/top", http.StatusOK, "GET /songs/top", ",,", ""},
+		{http.MethodDelete, "/songs/42", http.StatusOK, "DELETE /songs/{id}", "42,,", ""},
+		{http.MethodPatch, "/playlists/1/tracks/2", http.StatusOK, "PATCH /playlists/{id}/tracks/{song_id}", "1,2,", ""},
+		// HEAD falls back to GET, own HEAD handler is preferred
+		{http.MethodHead, "/songs/42", http.StatusOK, "GET /songs/{id}", "42,,", ""},
+		{http.MethodHead, "/artwork/a.jpg", http.StatusOK, "HEAD /artwork/{name}", ",,a.jpg", ""},
+		{http.MethodPut, "/songs", http.StatusMethodNotAllowed, "", "", "GET, HEAD, POST"},
+		{http.MethodPost, "/songs/42", http.StatusMethodNotAllowed, "", "", "DELETE, GET, HEAD"},
+		// DELETE is allowed for /songs/{id} that matches /songs/top too
+		{http.MethodPost, "/songs/top", http.StatusMethodNotAllowed, "", "", "DELETE, GET, HEAD"},
+		{http.MethodGet, "/unknown", http.StatusNotFound, "", "", ""},
+		{http.MethodGet, "/songs/42/extra", http.StatusNotFound, "", "", ""},
+		// parameter does not match empty segment
+		{http.MethodGet, "/songs/", http.StatusNotFound, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("X-Route"); got != tt.wantRoute {
+				t.Errorf("route = %q, want %q", got, tt.wantRoute)
+			}
+			if got := w.Header().Get("X-Params"); got != tt.wantParams {
+				t.Errorf("params = %q, want %q", got, tt.wantParams)
+			}
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestRouterHandleConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     Route
+		second    Route
+		wantPanic bool
+	}{
+		{"same pattern", Route{http.MethodGet, "/songs"}, Route{http.MethodGet, "/songs"}, true},
+		{"other parameter name", Route{http.MethodGet, "/songs/{id}"}, Route{http.MethodGet, "/songs/{song_id}"}, true},
+		{"other method", Route{http.MethodGet, "/songs"}, Route{http.MethodPost, "/songs"}, false},
+		{"static and parameter", Route{http.MethodGet, "/songs/{id}"}, Route{http.MethodGet, "/songs/top"}, false},
+		{"no leading slash", Route{http.MethodGet, "/songs"}, Route{http.MethodGet, "songs"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := New()
+			rt.Handle(tt.first.Method, tt.first.Pattern, http.NotFoundHandler())
+			defer func() {
+				if panicked := recover() != nil; panicked != tt.wantPanic {
+					t.Errorf("panicked = %v, want %v", panicked, tt.wantPanic)
+				}
+			}()
+			rt.Handle(tt.second.Method, tt.second.Pattern, http.NotFoundHandler())
+		})
+	}
+}
+
+func TestRouterRoutes(t *testing.T) {
+	rt := New()
+	rt.Handle(http.MethodGet, "/b", http.NotFoundHandler())
+	rt.Handle(http.MethodPost, "/a", http.NotFoundHandler())
+
+	routes := rt.Routes()
+	want := []Route{{http.MethodGet, "/b"}, {http.MethodPost, "/a"}}
+	if len(routes) != len(want) || routes[0] != want[0] || routes[1] != want[1] {
+		t.Errorf("Routes() = %v, want %v", routes, want)
+	}
+}
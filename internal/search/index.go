@@ -0,0 +1,330 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+// Field is bit mask of song fields term was found in
+type Field uint8
+
+const (
+	FieldName Field = 1 << iota
+	FieldBand
+	FieldAlbum
+)
+
+// weights of matches, exact term match is worth more than prefix or typo match
+const (
+	exactWeight  = 1.0
+	prefixWeight = 0.8
+	typo1Weight  = 0.6
+	typo2Weight  = 0.4
+)
+
+var fieldWeights = map[Field]float64{
+	FieldName:  3,
+	FieldBand:  2,
+	FieldAlbum: 1,
+}
+
+// Hit is single search result, Fields shows which song fields matched the query
+type Hit struct {
+	Song   globalStructs.Song
+	Score  float64
+	Fields Field
+}
+
+// Index is in-memory inverted index over song name, band and album
+type Index struct {
+	mu       sync.RWMutex
+	songs    map[string]globalStructs.Song
+	postings map[string]map[string]Field
+	// terms is sorted vocabulary used for prefix and typo lookups
+	terms []string
+	// loaded is set by first Reset, until then index has only songs added after start
+	loaded bool
+}
+
+func NewIndex() *Index {
+	return &Index{
+		songs:    make(map[string]globalStructs.Song),
+		postings: make(map[string]map[string]Field),
+	}
+}
+
+// Reset replaces whole index content with given songs
+func (i *Index) Reset(songs []globalStructs.Song) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.songs = make(map[string]globalStructs.Song, len(songs))
+	i.postings = make(map[string]map[string]Field)
+	i.terms = nil
+	for _, song := range songs {
+		i.add(song)
+	}
+	i.loaded = true
+}
+
+// Loaded reports whether whole catalog was put to index with Reset
+func (i *Index) Loaded() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.loaded
+}
+
+// Add indexes song, song with the same id is replaced
+func (i *Index) Add(song globalStructs.Song) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(song.ID)
+	i.add(song)
+}
+
+func (i *Index) Remove(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+}
+
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.songs)
+}
+
+func (i *Index) add(song globalStructs.Song) {
+	i.songs[song.ID] = song
+	for term, fields := range songTerms(song) {
+		posting, ok := i.postings[term]
+		if !ok {
+			posting = make(map[string]Field)
+			i.postings[term] = posting
+			pos := sort.SearchStrings(i.terms, term)
+			i.terms = append(i.terms, "")
+			copy(i.terms[pos+1:], i.terms[pos:])
+			i.terms[pos] = term
+		}
+		posting[song.ID] = fields
+	}
+}
+
+func (i *Index) remove(id string) {
+	song, ok := i.songs[id]
+	if !ok {
+		return
+	}
+	delete(i.songs, id)
+	for term := range songTerms(song) {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) != 0 {
+			continue
+		}
+		delete(i.postings, term)
+		pos := sort.SearchStrings(i.terms, term)
+		if pos < len(i.terms) && i.terms[pos] == term {
+			i.terms = append(i.terms[:pos], i.terms[pos+1:]...)
+		}
+	}
+}
+
+// Search returns songs matching every term of the query ordered by score.
+// Last term is matched as prefix so query can be used for typeahead,
+// terms longer than 3 letters tolerate typos.
+func (i *Index) Search(query string, limit int) []Hit {
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var hits map[string]*Hit
+	for n, queryTerm := range queryTerms {
+		best := make(map[string]float64)
+		matchedFields := make(map[string]Field)
+		for term, weight := range i.matchTerms(queryTerm, n == len(queryTerms)-1) {
+			for id, fields := range i.postings[term] {
+				if hits != nil && hits[id] == nil {
+					continue
+				}
+				matchedFields[id] |= fields
+				if score := weight * bestFieldWeight(fields); score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+
+		matched := make(map[string]*Hit, len(best))
+		for id, score := range best {
+			hit := hits[id]
+			if hit == nil {
+				hit = &Hit{Song: i.songs[id]}
+			}
+			hit.Score += score
+			hit.Fields |= matchedFields[id]
+			matched[id] = hit
+		}
+		hits = matched
+		if len(hits) == 0 {
+			return nil
+		}
+	}
+
+	result := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		result = append(result, *hit)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Score != result[b].Score {
+			return result[a].Score > result[b].Score
+		}
+		return result[a].Song.Name < result[b].Song.Name
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// matchTerms returns vocabulary terms matching query term with match weight
+func (i *Index) matchTerms(queryTerm string, prefix bool) map[string]float64 {
+	result := make(map[string]float64)
+	if _, ok := i.postings[queryTerm]; ok {
+		result[queryTerm] = exactWeight
+	}
+
+	if prefix {
+		for pos := sort.SearchStrings(i.terms, queryTerm); pos < len(i.terms) && strings.HasPrefix(i.terms[pos], queryTerm); pos++ {
+			if _, ok := result[i.terms[pos]]; !ok {
+				result[i.terms[pos]] = prefixWeight
+			}
+		}
+	}
+
+	maxEdits := allowedTypos(queryTerm)
+	if maxEdits == 0 {
+		return result
+	}
+	queryRunes := []rune(queryTerm)
+	for _, term := range i.terms {
+		if _, ok := result[term]; ok {
+			continue
+		}
+		distance := editDistance(queryRunes, []rune(term), maxEdits)
+		switch {
+		case distance == 1:
+			result[term] = typo1Weight
+		case distance == 2 && maxEdits == 2:
+			result[term] = typo2Weight
+		}
+	}
+	return result
+}
+
+func allowedTypos(term string) int {
+	switch l := len([]rune(term)); {
+	case l < 4:
+		return 0
+	case l < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is optimal string alignment distance between a and b,
+// max+1 is returned as soon as distance is known to be bigger than max
+func editDistance(a, b []rune, max int) int {
+	if d := len(a) - len(b); d > max || -d > max {
+		return max + 1
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < cur[j] {
+				cur[j] = prev2[j-2] + 1
+			}
+			if cur[j] < rowMin {
+				rowMin = cur[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func bestFieldWeight(fields Field) float64 {
+	var best float64
+	for field, weight := range fieldWeights {
+		if fields&field != 0 && weight > best {
+			best = weight
+		}
+	}
+	return best
+}
+
+func songTerms(song globalStructs.Song) map[string]Field {
+	terms := make(map[string]Field)
+	for field, text := range map[Field]string{FieldName: song.Name, FieldBand: song.Band, FieldAlbum: song.Album} {
+		for _, term := range tokenize(text) {
+			terms[term] |= field
+		}
+	}
+	return terms
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Get returns indexed song by id
+func (i *Index) Get(id string) (globalStructs.Song, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	song, ok := i.songs[id]
+	return song, ok
+}
+
+// Songs returns all indexed songs in no particular order
+func (i *Index) Songs() []globalStructs.Song {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	songs := make([]globalStructs.Song, 0, len(i.songs))
+	for _, song := range i.songs {
+		songs = append(songs, song)
+	}
+	return songs
+}
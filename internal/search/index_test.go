@@ -0,0 +1,142 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"queen", "queen", 2, 0},
+		{"queen", "quen", 2, 1},
+		{"queen", "queens", 2, 1},
+		{"queen", "qveen", 2, 1},
+		// transposition is one edit
+		{"queen", "qeuen", 2, 1},
+		{"metallica", "metalicca", 2, 2},
+		{"abba", "queen", 2, 3},
+		// length difference alone is over max
+		{"ab", "abcdef", 2, 3},
+		{"", "ab", 2, 2},
+		{"полька", "полка", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance([]rune(tt.a), []rune(tt.b), tt.max); got != tt.want {
+				t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"Bohemian Rhapsody", []string{"bohemian", "rhapsody"}},
+		{"AC/DC - Back in Black!", []string{"ac", "dc", "back", "in", "black"}},
+		{"Blink-182", []string{"blink", "182"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	index := NewIndex()
+	index.Reset([]globalStructs.Song{
+		{ID: "1", Name: "Bohemian Rhapsody", Band: "Queen", Album: "A Night at the Opera"},
+		{ID: "2", Name: "Queen of the Night", Band: "Whitney Houston", Album: "The Bodyguard"},
+		{ID: "3", Name: "Back in Black", Band: "AC/DC", Album: "Back in Black"},
+		{ID: "4", Name: "Hells Bells", Band: "AC/DC", Album: "Back in Black"},
+		{ID: "5", Name: "Master of Puppets", Band: "Metallica", Album: "Master of Puppets"},
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{"empty query", " ", 0, nil},
+		{"no match", "mozart", 0, nil},
+		// name is weighted over band and album
+		{"field weights", "queen", 0, []string{"2", "1"}},
+		{"every term has to match", "back black", 0, []string{"3", "4"}},
+		{"last term is prefix", "hells be", 0, []string{"4"}},
+		{"prefix only for last term", "bel hells", 0, nil},
+		{"exact is ranked over prefix", "night", 0, []string{"2", "1"}},
+		{"one typo", "metalica", 0, []string{"5"}},
+		{"two typos in long term", "rhapsdoyy", 0, []string{"1"}},
+		{"short terms have no typos", "ac/dx", 0, nil},
+		{"limit", "black", 1, []string{"3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, hit := range index.Search(tt.query, tt.limit) {
+				got = append(got, hit.Song.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexSearchFields(t *testing.T) {
+	index := NewIndex()
+	index.Add(globalStructs.Song{ID: "1", Name: "Black Dog", Band: "Led Zeppelin", Album: "Led Zeppelin IV"})
+
+	hits := index.Search("zeppelin", 0)
+	if len(hits) != 1 {
+		t.Fatalf("got %d hits, want 1", len(hits))
+	}
+	if want := FieldBand | FieldAlbum; hits[0].Fields != want {
+		t.Errorf("Fields = %b, want %b", hits[0].Fields, want)
+	}
+}
+
+func TestIndexAddRemove(t *testing.T) {
+	index := NewIndex()
+	if index.Loaded() {
+		t.Error("new index is loaded")
+	}
+	index.Reset(nil)
+	if !index.Loaded() {
+		t.Error("index is not loaded after Reset")
+	}
+
+	index.Add(globalStructs.Song{ID: "1", Name: "Yesterday", Band: "The Beatles"})
+	// adding song again replaces old terms
+	index.Add(globalStructs.Song{ID: "1", Name: "Let It Be", Band: "The Beatles"})
+	if hits := index.Search("yesterday", 0); len(hits) != 0 {
+		t.Errorf("old name is still found: %v", hits)
+	}
+	if hits := index.Search("let it be", 0); len(hits) != 1 {
+		t.Errorf("new name is not found: %v", hits)
+	}
+
+	index.Remove("1")
+	if index.Len() != 0 {
+		t.Errorf("Len() = %d after remove, want 0", index.Len())
+	}
+	if len(index.terms) != 0 || len(index.postings) != 0 {
+		t.Errorf("terms of removed song are kept: %v", index.terms)
+	}
+	if _, ok := index.Get("1"); ok {
+		t.Error("removed song is returned by Get")
+	}
+}
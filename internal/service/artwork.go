@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/floyernick/fleep-go"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/artwork"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+	"go.uber.org/zap"
+)
+
+const (
+	ArtworkKindSong  = "song"
+	ArtworkKindAlbum = "album"
+)
+
+func (s *Service) UploadArtwork(ctx context.Context, req structs.UploadArtworkReq) (resp structs.UploadArtworkResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UploadArtwork")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ID == "" || len(req.Data) == 0 {
+		resp.Error = "fill all the fields"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Kind != ArtworkKindSong && req.Kind != ArtworkKindAlbum {
+		resp.Error = "kind should be song or album"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	info, err := fleep.GetInfo(req.Data)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, apperr.Wrap(apperr.Validation, err)
+	}
+	if !info.IsImage() {
+		resp.Error = "file should be image"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	id, err := s.storeArtwork(ctx, req.Data)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	var respFromDB structs.SetArtworkResp
+	reqToDB := structs.SetArtworkReq{Kind: req.Kind, ID: req.ID, ArtworkID: id}
+	err = s.client.SendRequest(ctx, reqToDB, "post", "http://localhost:8082/api/v1/set_artwork", &respFromDB)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", reqToDB))
+		resp.Error = err.Error()
+		return
+	}
+	if respFromDB.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", respFromDB.Error))
+		resp.Error = respFromDB.Error
+		return resp, downstreamError(resp.Error)
+	}
+
+	resp.ArtworkID = id
+	resp.URLs = make(map[int]string, len(artwork.Sizes))
+	for _, size := range artwork.Sizes {
+		resp.URLs[size] = fmt.Sprintf("http://localhost:8080/artwork/%s", artwork.FileName(id, size))
+	}
+	return resp, nil
+}
+
+// storeArtwork resizes image to all artwork sizes and saves them to db
+func (s *Service) storeArtwork(ctx context.Context, data []byte) (string, error) {
+	// image passed fleep check, so failing to decode it is still fault of uploaded file
+	images, err := artwork.Resize(data, artwork.Sizes)
+	if err != nil {
+		s.log(ctx).Error("error resizing artwork", zap.Error(err))
+		return "", apperr.Wrap(apperr.Validation, err)
+	}
+
+	id := artwork.ID(data)
+	reqToDB := structs.AddArtworkReq{ID: id}
+	for _, size := range artwork.Sizes {
+		reqToDB.Images = append(reqToDB.Images, globalStructs.SongData{
+			ID:   artwork.FileName(id, size),
+			Data: images[size],
+		})
+	}
+
+	var respFromDB structs.AddArtworkResp
+	err = s.client.SendRequest(ctx, reqToDB, "post", "http://localhost:8082/api/v1/add_artwork", &respFromDB)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("id", id))
+		return "", err
+	}
+	if respFromDB.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", respFromDB.Error))
+		return "", downstreamError(respFromDB.Error)
+	}
+
+	return id, nil
+}
+
+// attachSongArtwork stores uploaded cover or the one embedded in mp3 tags,
+// failure is only logged since song itself is already saved
+func (s *Service) attachSongArtwork(ctx context.Context, songID string, cover, songData []byte) {
+	if len(cover) == 0 {
+		var ok bool
+		cover, ok = artwork.Extract(songData)
+		if !ok {
+			return
+		}
+	}
+
+	_, err := s.UploadArtwork(ctx, structs.UploadArtworkReq{Kind: ArtworkKindSong, ID: songID, Data: cover})
+	if err != nil {
+		s.log(ctx).Error("error attaching artwork to song", zap.Error(err), zap.Any("song_id", songID))
+	}
+}
+
+func (s *Service) GetArtwork(ctx context.Context, id string) (image []byte, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetArtwork")
+	defer func() { tracing.End(span, err) }()
+
+	if id == "" {
+		return nil, apperr.New(apperr.Validation, "you must fill artwork id")
+	}
+
+	var resp structs.GetArtworkResp
+	err = s.client.SendRequest(ctx, structs.GetArtworkReq{ID: id}, "post", "http://localhost:8082/api/v1/get_artwork", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("id", id))
+		return nil, err
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return nil, downstreamError(resp.Error)
+	}
+
+	return resp.Image.Data, nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+// normalizeName makes artist names comparable, so "The Beatles " and "the beatles" are the same
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// artistNames resolves band names of songs to canonical artists by normalized name and aliases
+type artistNames struct {
+	mu sync.RWMutex
+	// names is canonical artist name by normalized name and by every alias
+	names map[string]string
+}
+
+func newArtistNames() *artistNames {
+	return &artistNames{names: make(map[string]string)}
+}
+
+// reset replaces known artists with artists
+func (a *artistNames) reset(artists []structs.Artist) {
+	names := make(map[string]string, len(artists))
+	for _, artist := range artists {
+		addArtistName(names, artist)
+	}
+	a.mu.Lock()
+	a.names = names
+	a.mu.Unlock()
+}
+
+func (a *artistNames) add(artist structs.Artist) {
+	a.mu.Lock()
+	addArtistName(a.names, artist)
+	a.mu.Unlock()
+}
+
+func addArtistName(names map[string]string, artist structs.Artist) {
+	names[normalizeName(artist.Name)] = artist.Name
+	for _, alias := range artist.Aliases {
+		// alias never takes over name of other artist
+		if _, ok := names[normalizeName(alias)]; !ok {
+			names[normalizeName(alias)] = artist.Name
+		}
+	}
+}
+
+// canonical returns name of artist band belongs to, unknown bands are returned as is
+func (a *artistNames) canonical(band string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if name, ok := a.names[normalizeName(band)]; ok {
+		return name
+	}
+	return band
+}
+
+// key returns normalized name of artist band belongs to, bands of the same artist have the same key
+func (a *artistNames) key(band string) string {
+	return normalizeName(a.canonical(band))
+}
+
+// loadArtists loads artists bands are grouped by, failed load keeps artists known before
+func (s *Service) loadArtists(ctx context.Context) {
+	var resp structs.GetAllArtistsResp
+	err := s.client.SendRequest(ctx, nil, "get", "http://localhost:8082/api/v1/all_artists", &resp)
+	if err == nil && resp.Error != "" {
+		err = downstreamError(resp.Error)
+	}
+	if err != nil {
+		s.log(ctx).Error("error loading artists", zap.Error(err))
+		return
+	}
+	s.artists.reset(resp.Artists)
+}
+
+func (s *Service) NewArtist(ctx context.Context, req structs.NewArtistReq) (resp structs.NewArtistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.NewArtist")
+	defer func() { tracing.End(span, err) }()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		resp.Error = "you must fill artist name"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	// aliases are stored normalized and without duplicates of the name
+	seen := map[string]bool{normalizeName(req.Name): true}
+	var aliases []string
+	for _, alias := range req.Aliases {
+		alias = normalizeName(alias)
+		if alias == "" || seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	artist := structs.Artist{
+		Name:    req.Name,
+		Key:     normalizeName(req.Name),
+		Aliases: aliases,
+		Artwork: req.Artwork,
+	}
+
+	err = s.client.SendRequest(ctx, artist, "post", "http://localhost:8082/api/v1/new_artist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", artist))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.artists.add(resp.Artist)
+	return
+}
+
+func (s *Service) GetArtist(ctx context.Context, req structs.GetArtistReq) (resp structs.GetArtistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetArtist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ID == "" && req.Name == "" {
+		resp.Error = "you must fill artist id or name"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	// alias known here is resolved to artist key, db also matches aliases it has
+	dbReq := structs.GetArtistDBReq{ID: req.ID}
+	if req.ID == "" {
+		dbReq.Key = s.artists.key(req.Name)
+	}
+
+	err = s.client.SendRequest(ctx, dbReq, "post", "http://localhost:8082/api/v1/get_artist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", dbReq))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) NewAlbum(ctx context.Context, req structs.NewAlbumReq) (resp structs.NewAlbumResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.NewAlbum")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ArtistID == "" || strings.TrimSpace(req.Name) == "" {
+		resp.Error = "you must fill artist id and album name"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	numbers := make(map[int]bool)
+	songs := make(map[string]bool)
+	for _, track := range req.Tracks {
+		if track.SongID == "" || track.Number <= 0 {
+			resp.Error = "every track should have song id and positive number"
+			return resp, apperr.New(apperr.Validation, resp.Error)
+		}
+		if numbers[track.Number] || songs[track.SongID] {
+			resp.Error = fmt.Sprintf("duplicate track %d %s", track.Number, track.SongID)
+			return resp, apperr.New(apperr.Validation, resp.Error)
+		}
+		numbers[track.Number] = true
+		songs[track.SongID] = true
+	}
+	sort.Slice(req.Tracks, func(i, j int) bool {
+		return req.Tracks[i].Number < req.Tracks[j].Number
+	})
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/new_album", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) GetArtistAlbums(ctx context.Context, req structs.GetArtistAlbumsReq) (resp structs.GetArtistAlbumsResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetArtistAlbums")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ArtistID == "" {
+		resp.Error = "you must fill artist id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/artist_albums", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) GetAlbumTracks(ctx context.Context, req structs.GetAlbumTracksReq) (resp structs.GetAlbumTracksResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAlbumTracks")
+	defer func() { tracing.End(span, err) }()
+
+	if req.AlbumID == "" {
+		resp.Error = "you must fill album id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/album_tracks", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	// db returns songs in any order, put them in album track order
+	number := make(map[string]int, len(resp.Album.Tracks))
+	for _, track := range resp.Album.Tracks {
+		number[track.SongID] = track.Number
+	}
+	sort.SliceStable(resp.Songs, func(i, j int) bool {
+		return number[resp.Songs[i].ID] < number[resp.Songs[j].ID]
+	})
+
+	return
+}
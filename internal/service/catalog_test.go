@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/charts"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/playlistfmt"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+var beatles = structs.Artist{ID: "a1", Name: "The Beatles", Key: "the beatles", Aliases: []string{"beatles", "fab four"}}
+
+func TestArtistNames(t *testing.T) {
+	names := newArtistNames()
+	names.reset([]structs.Artist{
+		beatles,
+		// alias of other artist does not take over existing name
+		{Name: "Beatles", Aliases: []string{"the beatles"}},
+	})
+
+	tests := []struct {
+		band          string
+		wantCanonical string
+		wantKey       string
+	}{
+		{"The Beatles", "The Beatles", "the beatles"},
+		{"  THE   beatles ", "The Beatles", "the beatles"},
+		{"Fab Four", "The Beatles", "the beatles"},
+		{"Beatles", "Beatles", "beatles"},
+		{"Abba ", "Abba ", "abba"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.band, func(t *testing.T) {
+			if got := names.canonical(tt.band); got != tt.wantCanonical {
+				t.Errorf("canonical() = %q, want %q", got, tt.wantCanonical)
+			}
+			if got := names.key(tt.band); got != tt.wantKey {
+				t.Errorf("key() = %q, want %q", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestNewArtist(t *testing.T) {
+	var sent structs.Artist
+	s, _ := newTestService(t, map[string]route{
+		"/api/v1/new_artist": func(body []byte) interface{} {
+			decode(t, body, &sent)
+			artist := sent
+			artist.ID = "a1"
+			return structs.NewArtistResp{Artist: artist}
+		},
+	})
+
+	_, err := s.NewArtist(context.Background(), structs.NewArtistReq{Name: " The  Beatles ", Aliases: []string{"Fab Four", "the beatles", " FAB four", ""}})
+	if err != nil {
+		t.Fatalf("NewArtist() error = %v", err)
+	}
+	want := structs.Artist{Name: "The  Beatles", Key: "the beatles", Aliases: []string{"fab four"}}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %+v, want %+v", sent, want)
+	}
+	if got := s.artists.canonical("fab four"); got != "The  Beatles" {
+		t.Errorf("new artist alias resolves to %q", got)
+	}
+
+	if _, err := s.NewArtist(context.Background(), structs.NewArtistReq{Name: "  "}); apperr.CodeOf(err) != apperr.Validation {
+		t.Errorf("empty name err = %v, want validation", err)
+	}
+}
+
+func TestGetArtist(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      structs.GetArtistReq
+		want     structs.GetArtistDBReq
+		wantCode apperr.Code
+	}{
+		{name: "by id", req: structs.GetArtistReq{ID: "a1", Name: "ignored"}, want: structs.GetArtistDBReq{ID: "a1"}},
+		{name: "by name", req: structs.GetArtistReq{Name: " the BEATLES"}, want: structs.GetArtistDBReq{Key: "the beatles"}},
+		{name: "by alias", req: structs.GetArtistReq{Name: "Fab Four"}, want: structs.GetArtistDBReq{Key: "the beatles"}},
+		// db matches aliases service does not know yet
+		{name: "unknown name", req: structs.GetArtistReq{Name: "ABBA"}, want: structs.GetArtistDBReq{Key: "abba"}},
+		{name: "no id or name", req: structs.GetArtistReq{}, wantCode: apperr.Validation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent structs.GetArtistDBReq
+			s, _ := newTestService(t, map[string]route{
+				"/api/v1/get_artist": func(body []byte) interface{} {
+					decode(t, body, &sent)
+					return structs.GetArtistResp{Artist: beatles}
+				},
+			})
+			s.artists.reset([]structs.Artist{beatles})
+
+			_, err := s.GetArtist(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+			} else if err != nil {
+				t.Fatalf("GetArtist() error = %v", err)
+			}
+			if sent != tt.want {
+				t.Errorf("sent %+v, want %+v", sent, tt.want)
+			}
+		})
+	}
+}
+
+// TestArtistGrouping checks songs tagged with artist alias are grouped under artist name
+func TestArtistGrouping(t *testing.T) {
+	s, _ := newTestService(t, map[string]route{
+		"/api/v1/all_artists": answer(structs.GetAllArtistsResp{Artists: []structs.Artist{beatles}}),
+		"/api/v1/allsongs": answer(structsDB.GetAllSongsResp{Songs: []globalStructs.Song{
+			{ID: "1", Name: "Yesterday", Band: "The Beatles", Album: "Help!"},
+			{ID: "2", Name: "Help", Band: "Beatles", Album: "Help!"},
+			{ID: "3", Name: "Waterloo", Band: "Abba", Album: "Waterloo"},
+		}}),
+	})
+	if err := s.loadSearchIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("search", func(t *testing.T) {
+		resp, err := s.Search(context.Background(), structs.SearchReq{Query: "beatles"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"The Beatles"}; !reflect.DeepEqual(resp.Artists, want) {
+			t.Errorf("artists = %v, want %v", resp.Artists, want)
+		}
+	})
+
+	t.Run("top artists", func(t *testing.T) {
+		for _, songID := range []string{"1", "2", "3"} {
+			s.addToCharts(structs.PlayEvent{UserID: "u1", SongID: songID, Type: PlayEventComplete, Counted: true, CreatedAt: time.Now()})
+		}
+		resp, err := s.GetTopArtists(context.Background(), structs.TopReq{UserID: "u1", Range: charts.RangeShortTerm})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []structs.ArtistPlays{{Artist: "The Beatles", Plays: 2}, {Artist: "Abba", Plays: 1}}
+		if !reflect.DeepEqual(resp.Artists, want) {
+			t.Errorf("top artists = %v, want %v", resp.Artists, want)
+		}
+	})
+
+	t.Run("radio artist seed", func(t *testing.T) {
+		// both beatles songs are seeds, so only popular abba song is left for radio
+		resp, err := s.GetRadio(context.Background(), structs.RadioReq{Artist: "fab four", Limit: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Songs) != 1 || resp.Songs[0].ID != "3" {
+			t.Errorf("radio = %v, want only song 3", resp.Songs)
+		}
+	})
+
+	t.Run("import matcher", func(t *testing.T) {
+		matcher := newSongMatcher(s.index.Songs(), s.artists)
+		tests := []struct {
+			entry  playlistfmt.Entry
+			wantID string
+		}{
+			{playlistfmt.Entry{Title: "help", Artist: "The Beatles"}, "2"},
+			{playlistfmt.Entry{Title: "Yesterday", Artist: "Fab Four"}, "1"},
+			{playlistfmt.Entry{Title: "Waterloo", Artist: "Beatles"}, ""},
+		}
+		for _, tt := range tests {
+			song, ok := matcher.match(tt.entry)
+			if ok != (tt.wantID != "") || song.ID != tt.wantID {
+				t.Errorf("match(%+v) = %q, %v, want %q", tt.entry, song.ID, ok, tt.wantID)
+			}
+		}
+	})
+}
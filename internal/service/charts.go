@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/charts"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/metrics"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultChartLimit = 50
+	maxChartLimit     = 200
+)
+
+// loadCharts fills charts with play events saved in db before chartsCutoff, later events
+// are counted when they are saved, so events saved during the load are not counted twice.
+// Artists are taken from search index so it has to be loaded first.
+func (s *Service) loadCharts() {
+	ctx, span := tracer.Start(s.ctx, "Service.loadCharts")
+	defer span.End()
+
+	var resp structs.GetPlayEventsResp
+	err := s.client.SendRequest(ctx, structs.GetPlayEventsReq{}, "post", "http://localhost:8082/api/v1/play_events", &resp)
+	if err != nil {
+		s.log(ctx).Error("error loading play events", zap.Error(err))
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return
+	}
+
+	loaded := 0
+	for _, event := range resp.Events {
+		if event.CreatedAt.Before(s.chartsCutoff) {
+			s.addToCharts(event)
+			loaded++
+		}
+	}
+	s.log(ctx).Info("charts loaded", zap.Int("events", loaded))
+}
+
+// addLiveToCharts counts event just saved, events before chartsCutoff are counted by loadCharts
+func (s *Service) addLiveToCharts(event structs.PlayEvent) {
+	if !event.CreatedAt.Before(s.chartsCutoff) {
+		s.addToCharts(event)
+	}
+}
+
+func (s *Service) addToCharts(event structs.PlayEvent) {
+	if event.Type == PlayEventStart || event.Type == PlayEventInferred {
+		s.charts.AddRecent(event.UserID, event.SongID, event.CreatedAt)
+	}
+	if event.Counted {
+		song, _ := s.index.Get(event.SongID)
+		s.charts.AddPlay(event.UserID, event.SongID, s.artists.canonical(song.Band), event.CreatedAt)
+	}
+}
+
+func chartLimit(limit int) int {
+	if limit <= 0 {
+		return defaultChartLimit
+	}
+	if limit > maxChartLimit {
+		return maxChartLimit
+	}
+	return limit
+}
+
+// songOrID returns song from catalog, songs missing in catalog have only id
+func (s *Service) songOrID(id string) globalStructs.Song {
+	song, ok := s.index.Get(id)
+	metrics.CacheLookup(metrics.CacheCatalog, ok)
+	if !ok {
+		song.ID = id
+	}
+	return song
+}
+
+func (s *Service) GetRecentlyPlayed(ctx context.Context, req structs.RecentlyPlayedReq) (resp structs.RecentlyPlayedResp, err error) {
+	_, span := tracer.Start(ctx, "Service.GetRecentlyPlayed")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" {
+		resp.Error = "you must fill user id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	for _, play := range s.charts.RecentlyPlayed(req.UserID, chartLimit(req.Limit)) {
+		resp.Songs = append(resp.Songs, structs.RecentlyPlayedSong{
+			Song:     s.songOrID(play.SongID),
+			PlayedAt: play.PlayedAt,
+		})
+	}
+	return resp, nil
+}
+
+func (s *Service) GetTopTracks(ctx context.Context, req structs.TopReq) (resp structs.TopTracksResp, err error) {
+	_, span := tracer.Start(ctx, "Service.GetTopTracks")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" {
+		resp.Error = "you must fill user id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Range == "" {
+		req.Range = charts.RangeShortTerm
+	}
+
+	top, err := s.charts.TopSongs(req.UserID, req.Range, chartLimit(req.Limit))
+	if err != nil {
+		// only error is unknown range sent by client
+		resp.Error = err.Error()
+		return resp, apperr.Wrap(apperr.Validation, err)
+	}
+	for _, count := range top {
+		resp.Songs = append(resp.Songs, structs.SongPlays{Song: s.songOrID(count.Key), Plays: count.Plays})
+	}
+	return resp, nil
+}
+
+func (s *Service) GetTopArtists(ctx context.Context, req structs.TopReq) (resp structs.TopArtistsResp, err error) {
+	_, span := tracer.Start(ctx, "Service.GetTopArtists")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" {
+		resp.Error = "you must fill user id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Range == "" {
+		req.Range = charts.RangeShortTerm
+	}
+
+	top, err := s.charts.TopArtists(req.UserID, req.Range, chartLimit(req.Limit))
+	if err != nil {
+		// only error is unknown range sent by client
+		resp.Error = err.Error()
+		return resp, apperr.Wrap(apperr.Validation, err)
+	}
+	for _, count := range top {
+		resp.Artists = append(resp.Artists, structs.ArtistPlays{Artist: count.Key, Plays: count.Plays})
+	}
+	return resp, nil
+}
+
+func (s *Service) GetCharts(ctx context.Context, req structs.ChartsReq) (resp structs.ChartsResp, err error) {
+	_, span := tracer.Start(ctx, "Service.GetCharts")
+	defer func() { tracing.End(span, err) }()
+
+	if req.Window == "" {
+		req.Window = charts.WindowWeek
+	}
+
+	top, err := s.charts.Chart(req.Window, chartLimit(req.Limit))
+	if err != nil {
+		// only error is unknown range sent by client
+		resp.Error = err.Error()
+		return resp, apperr.Wrap(apperr.Validation, err)
+	}
+	for _, count := range top {
+		resp.Songs = append(resp.Songs, structs.SongPlays{Song: s.songOrID(count.Key), Plays: count.Plays})
+	}
+	return resp, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/charts"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+)
+
+func TestLoadChartsSkipsLiveEvents(t *testing.T) {
+	s, f := newTestService(t, map[string]route{
+		"/api/v1/add_play_event": answer(structs.PlayEventResp{OK: true}),
+	})
+
+	// play saved while events are loaded is returned by db and counted live
+	if _, err := s.RecordPlayEvent(context.Background(), structs.PlayEventReq{UserID: "u1", SongID: "s1", Type: PlayEventComplete}); err != nil {
+		t.Fatal(err)
+	}
+	live := structs.PlayEvent{UserID: "u1", SongID: "s1", Type: PlayEventComplete, Counted: true, CreatedAt: time.Now().Truncate(time.Millisecond)}
+	f.handle("/api/v1/play_events", answer(structs.GetPlayEventsResp{Events: []structs.PlayEvent{
+		{UserID: "u1", SongID: "s1", Type: PlayEventComplete, Counted: true, CreatedAt: s.chartsCutoff.Add(-time.Hour)},
+		{UserID: "u2", SongID: "s2", Type: PlayEventInferred, Counted: true, CreatedAt: s.chartsCutoff.Add(-time.Millisecond)},
+		live,
+	}}))
+	s.loadCharts()
+
+	tests := []struct {
+		songID string
+		want   int
+	}{
+		{"s1", 2},
+		{"s2", 1},
+	}
+	top, err := s.charts.Chart(charts.WindowWeek, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.songID, func(t *testing.T) {
+			for _, count := range top {
+				if count.Key == tt.songID {
+					if count.Plays != tt.want {
+						t.Errorf("plays = %d, want %d", count.Plays, tt.want)
+					}
+					return
+				}
+			}
+			t.Errorf("song is not in chart %v", top)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/client"
+)
+
+// downstreamPolicies are timeouts and retries of db and auth endpoints, others use client.DefaultPolicy.
+// Only reads are retried, writes could be applied twice.
+var downstreamPolicies = map[string]client.Policy{
+	// segments are fetched by players all the time, they should fail fast
+	"/api/v1/getsegment":      {Timeout: 3 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/allsongs":        {Timeout: 30 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/all_artists":     {Timeout: 10 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/songs":           {Timeout: 5 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/get_playlist":    {Timeout: 5 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/user_playlists":  {Timeout: 5 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/playlist_access": {Timeout: 3 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/get_artwork":     {Timeout: 3 * time.Second, Retries: 2, Idempotent: true},
+	"/api/v1/play_events":     {Timeout: 60 * time.Second, Retries: 1, Idempotent: true},
+	"/api/v1/all_playlists":   {Timeout: 60 * time.Second, Retries: 1, Idempotent: true},
+	// song upload sends all segments in one request
+	"/api/v1/addSegment":  {Timeout: 2 * time.Minute},
+	"/api/v1/add_artwork": {Timeout: 30 * time.Second},
+}
+
+// DownstreamStates returns circuit breaker state of every downstream host
+func (s *Service) DownstreamStates(ctx context.Context) map[string]string {
+	_, span := tracer.Start(ctx, "Service.DownstreamStates")
+	defer span.End()
+
+	return s.client.BreakerStates()
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+)
+
+// downstreamError gives code to error db or auth sent in response body.
+// They send errors of mongo as is, so missing and duplicate documents are told by message.
+func downstreamError(message string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "no documents"), strings.Contains(lower, "not found"):
+		return apperr.New(apperr.NotFound, message)
+	case strings.Contains(lower, "duplicate key"), strings.Contains(lower, "already exists"):
+		return apperr.New(apperr.Conflict, message)
+	}
+	return apperr.New(apperr.Internal, message)
+}
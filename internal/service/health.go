@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/client"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+)
+
+// readinessTimeout bounds all readiness checks together
+const readinessTimeout = 2 * time.Second
+
+// readiness dependencies
+const (
+	DependencyDB         = "db"
+	DependencyAuth       = "auth"
+	DependencyFFmpeg     = "ffmpeg"
+	DependencyWorkingDir = "working_dir"
+	// DependencySearchIndex is catalog loaded to memory, search and imports are empty without it
+	DependencySearchIndex = "search_index"
+)
+
+// CheckReadiness checks all dependencies in parallel and reports circuit breaker states aside,
+// breaker opened by failed requests does not make service not ready by itself
+func (s *Service) CheckReadiness(ctx context.Context) (resp structs.ReadinessResp) {
+	ctx, span := tracer.Start(ctx, "Service.CheckReadiness")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	checks := map[string]func(context.Context) error{
+		DependencyDB:          s.checkDownstream("http://localhost:8082/"),
+		DependencyAuth:        s.checkDownstream("http://localhost:8083/"),
+		DependencyFFmpeg:      checkFFmpeg,
+		DependencyWorkingDir:  checkWorkingDir,
+		DependencySearchIndex: s.checkSearchIndex,
+	}
+
+	resp.Ready = true
+	resp.Dependencies = make(map[string]structs.DependencyStatus, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			started := time.Now()
+			err := check(ctx)
+			status := structs.DependencyStatus{OK: err == nil, LatencyMs: time.Since(started).Milliseconds()}
+			if err != nil {
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Dependencies[name] = status
+			if err != nil {
+				resp.Ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	resp.Breakers = s.client.BreakerStates()
+
+	return resp
+}
+
+// checkDownstream makes sure service answers, any answer but 5xx is fine. Probe goes
+// around circuit breaker, so open breaker does not hide recovered service and probes do not trip it.
+func (s *Service) checkDownstream(url string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := s.probe.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return &client.StatusError{URL: url, Code: resp.StatusCode}
+		}
+		return nil
+	}
+}
+
+// checkSearchIndex makes sure catalog was loaded to search index
+func (s *Service) checkSearchIndex(context.Context) error {
+	if !s.index.Loaded() {
+		return errors.New("search index is not loaded")
+	}
+	return nil
+}
+
+// checkFFmpeg makes sure songs can be transcoded
+func checkFFmpeg(context.Context) error {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return err
+	}
+	_, err := os.Stat(utils.TranscodeScript)
+	return err
+}
+
+// checkWorkingDir makes sure upload files can be written
+func checkWorkingDir(context.Context) error {
+	for _, dir := range []string{utils.MP3Dir, utils.SegmentsDir} {
+		f, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		if err := os.Remove(f.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
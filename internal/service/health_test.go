@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/client"
+)
+
+func TestCheckReadinessDownstream(t *testing.T) {
+	tests := []struct {
+		name string
+		// failures are requests failed before check, five of them open breaker of db
+		failures    int
+		probe       route
+		wantOK      bool
+		wantBreaker string
+	}{
+		{name: "answers", probe: answer(map[string]string{}), wantOK: true},
+		{name: "answers with open breaker", failures: 5, probe: answer(map[string]string{}), wantOK: true, wantBreaker: client.StateOpen},
+		// failed probes are not counted by breaker
+		{name: "down", probe: answer(errDown)},
+		{name: "down with failed requests", failures: 2, probe: answer(errDown), wantBreaker: client.StateClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, map[string]route{
+				"/":              tt.probe,
+				"/api/v1/failed": answer(errDown),
+			})
+			for i := 0; i < tt.failures; i++ {
+				s.client.Get(context.Background(), "http://localhost:8082/api/v1/failed")
+			}
+
+			resp := s.CheckReadiness(context.Background())
+			for _, name := range []string{DependencyDB, DependencyAuth} {
+				if status := resp.Dependencies[name]; status.OK != tt.wantOK {
+					t.Errorf("%s = %+v, want ok %v", name, status, tt.wantOK)
+				}
+			}
+			if got := resp.Breakers["localhost:8082"]; got != tt.wantBreaker {
+				t.Errorf("db breaker = %q, want %q", got, tt.wantBreaker)
+			}
+			if _, ok := resp.Breakers["localhost:8083"]; ok {
+				t.Error("auth probe went through breaker")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	"go.uber.org/zap"
+)
+
+// playlist history actions
+const (
+	ActionAddSong     = "add_song"
+	ActionRemoveSong  = "remove_song"
+	ActionAddSongs    = "add_songs"
+	ActionRemoveSongs = "remove_songs"
+	ActionMoveSong    = "move_song"
+	ActionUpdate      = "update"
+	ActionRestore     = "restore"
+	// ActionDeleteSong is song deleted from catalog, version of it has no user
+	ActionDeleteSong = "delete_song"
+)
+
+// fetchPlaylist gets playlist from db without access checks
+func (s *Service) fetchPlaylist(ctx context.Context, playlistID, ownerID string) (resp structsDB.GetPlaylistResp, err error) {
+	req := structsDB.GetPlaylistReq{PlaylistID: playlistID, UserID: ownerID}
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/get_playlist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+	return
+}
+
+// snapshotPlaylist takes playlist state before change, so the first change of
+// playlist can be undone too. Failure is only logged and change is not recorded then.
+func (s *Service) snapshotPlaylist(ctx context.Context, playlistID, ownerID string) *structs.PlaylistVersion {
+	playlist, err := s.fetchPlaylist(ctx, playlistID, ownerID)
+	if err != nil {
+		s.log(ctx).Error("error getting playlist for history", zap.Error(err), zap.String("playlist_id", playlistID))
+		return nil
+	}
+	return &structs.PlaylistVersion{
+		PlaylistID: playlistID,
+		Name:       playlist.Playlist.Name,
+		SongIDs:    playlist.Playlist.SongIDs,
+	}
+}
+
+// recordPlaylistVersion saves snapshot taken before change made by userID.
+// Change is already done, so failure is only logged.
+func (s *Service) recordPlaylistVersion(ctx context.Context, snapshot *structs.PlaylistVersion, userID, action string, changed []string) {
+	if snapshot == nil {
+		return
+	}
+	version := *snapshot
+	version.UserID = userID
+	version.Action = action
+	version.ChangedSongIDs = changed
+	version.CreatedAt = time.Now()
+
+	var resp structs.AddPlaylistVersionResp
+	err := s.client.SendRequest(ctx, version, "post", "http://localhost:8082/api/v1/add_playlist_version", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.String("playlist_id", version.PlaylistID))
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+	}
+}
+
+func (s *Service) GetPlaylistHistory(ctx context.Context, req structs.GetPlaylistHistoryReq) (resp structs.GetPlaylistHistoryResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetPlaylistHistory")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	if _, err = s.authorizePlaylist(ctx, req.PlaylistID, req.UserID, false); err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/playlist_history", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) RestorePlaylistVersion(ctx context.Context, req structs.RestorePlaylistVersionReq) (resp structs.RestorePlaylistVersionResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.RestorePlaylistVersion")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Version <= 0 {
+		resp.Error = "version should be positive"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, req.UserID, true)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	var respVersion structs.GetPlaylistVersionResp
+	reqVersion := structs.GetPlaylistVersionReq{PlaylistID: req.PlaylistID, Version: req.Version}
+	err = s.client.SendRequest(ctx, reqVersion, "post", "http://localhost:8082/api/v1/playlist_version", &respVersion)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", reqVersion))
+		resp.Error = err.Error()
+		return
+	}
+	if respVersion.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", respVersion.Error))
+		resp.Error = respVersion.Error
+		return resp, downstreamError(resp.Error)
+	}
+
+	snapshot := s.snapshotPlaylist(ctx, req.PlaylistID, ownerID)
+	reqToDB := structs.SetPlaylistSongsReq{
+		UserID:     ownerID,
+		PlaylistID: req.PlaylistID,
+		Name:       respVersion.Version.Name,
+		SongIDs:    respVersion.Version.SongIDs,
+	}
+	err = s.client.SendRequest(ctx, reqToDB, "post", "http://localhost:8082/api/v1/set_playlist_songs", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	// restore is recorded too, so it can be undone as well
+	s.recordPlaylistVersion(ctx, snapshot, req.UserID, ActionRestore, nil)
+	return
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+func TestSnapshotPlaylist(t *testing.T) {
+	tests := []struct {
+		name     string
+		playlist route
+		// want is version recorded, empty when change is not recorded
+		want structs.PlaylistVersion
+	}{
+		{
+			name: "recorded",
+			playlist: answer(structsDB.GetPlaylistResp{Playlist: globalStructs.Playlist{
+				ID: "p1", Name: "Mix", SongIDs: []string{"1", "2"},
+			}}),
+			want: structs.PlaylistVersion{PlaylistID: "p1", UserID: "u2", Action: ActionAddSong, ChangedSongIDs: []string{"3"}, Name: "Mix", SongIDs: []string{"1", "2"}},
+		},
+		// change is done anyway, only history misses it
+		{name: "playlist not read", playlist: answer(errDown)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded structs.PlaylistVersion
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/get_playlist": tt.playlist,
+				"/api/v1/add_playlist_version": func(body []byte) interface{} {
+					decode(t, body, &recorded)
+					return structs.AddPlaylistVersionResp{Version: 1}
+				},
+			})
+
+			snapshot := s.snapshotPlaylist(context.Background(), "p1", "u1")
+			s.recordPlaylistVersion(context.Background(), snapshot, "u2", ActionAddSong, []string{"3"})
+
+			if tt.want.PlaylistID == "" {
+				if f.count("/api/v1/add_playlist_version") != 0 {
+					t.Errorf("recorded %+v without snapshot", recorded)
+				}
+				return
+			}
+			if recorded.CreatedAt.IsZero() {
+				t.Error("version has no time")
+			}
+			recorded.CreatedAt = tt.want.CreatedAt
+			if !reflect.DeepEqual(recorded, tt.want) {
+				t.Errorf("recorded %+v, want %+v", recorded, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestorePlaylistVersion(t *testing.T) {
+	version := answer(structs.GetPlaylistVersionResp{Version: structs.PlaylistVersion{
+		PlaylistID: "p1", Version: 3, Name: "Old mix", SongIDs: []string{"1", "2", "3"},
+	}})
+
+	tests := []struct {
+		name     string
+		req      structs.RestorePlaylistVersionReq
+		access   structs.PlaylistAccess
+		version  route
+		wantCode apperr.Code
+	}{
+		{name: "owner", req: structs.RestorePlaylistVersionReq{UserID: "u1", PlaylistID: "p1", Version: 3}, access: structs.PlaylistAccess{OwnerID: "u1"}, version: version},
+		{name: "editor", req: structs.RestorePlaylistVersionReq{UserID: "u2", PlaylistID: "p1", Version: 3}, access: structs.PlaylistAccess{OwnerID: "u1", Role: RoleEditor}, version: version},
+		{name: "viewer", req: structs.RestorePlaylistVersionReq{UserID: "u2", PlaylistID: "p1", Version: 3}, access: structs.PlaylistAccess{OwnerID: "u1", Role: RoleViewer}, version: version, wantCode: apperr.Forbidden},
+		{name: "unknown version", req: structs.RestorePlaylistVersionReq{UserID: "u1", PlaylistID: "p1", Version: 9}, access: structs.PlaylistAccess{OwnerID: "u1"}, version: answer(structs.GetPlaylistVersionResp{Error: "mongo: no documents in result"}), wantCode: apperr.NotFound},
+		{name: "no version", req: structs.RestorePlaylistVersionReq{UserID: "u1", PlaylistID: "p1"}, wantCode: apperr.Validation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var restored structs.SetPlaylistSongsReq
+			var recorded structs.PlaylistVersion
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/playlist_access":  answer(structs.GetPlaylistAccessResp{Access: tt.access}),
+				"/api/v1/playlist_version": tt.version,
+				"/api/v1/get_playlist": answer(structsDB.GetPlaylistResp{Playlist: globalStructs.Playlist{
+					ID: "p1", Name: "Mix", SongIDs: []string{"1"},
+				}}),
+				"/api/v1/set_playlist_songs": func(body []byte) interface{} {
+					decode(t, body, &restored)
+					return structs.RestorePlaylistVersionResp{OK: true}
+				},
+				"/api/v1/add_playlist_version": func(body []byte) interface{} {
+					decode(t, body, &recorded)
+					return structs.AddPlaylistVersionResp{Version: 4}
+				},
+			})
+
+			_, err := s.RestorePlaylistVersion(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				if f.count("/api/v1/set_playlist_songs") != 0 {
+					t.Error("playlist is changed on failed restore")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RestorePlaylistVersion() error = %v", err)
+			}
+
+			wantRestored := structs.SetPlaylistSongsReq{UserID: "u1", PlaylistID: "p1", Name: "Old mix", SongIDs: []string{"1", "2", "3"}}
+			if !reflect.DeepEqual(restored, wantRestored) {
+				t.Errorf("restored %+v, want %+v", restored, wantRestored)
+			}
+			// state before restore is recorded, so restore can be undone
+			if recorded.Action != ActionRestore || recorded.UserID != tt.req.UserID || recorded.Name != "Mix" || !reflect.DeepEqual(recorded.SongIDs, []string{"1"}) {
+				t.Errorf("recorded %+v, want state before restore by %s", recorded, tt.req.UserID)
+			}
+		})
+	}
+}
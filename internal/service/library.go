@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+// maxLikedCheck is max number of songs checked by one AreSongsLiked call
+const maxLikedCheck = 100
+
+func (s *Service) LikeSong(ctx context.Context, req structs.LikeSongReq) (resp structs.LikeSongResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.LikeSong")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" || req.SongID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/like_song", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) SaveAlbum(ctx context.Context, req structs.SaveAlbumReq) (resp structs.SaveAlbumResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SaveAlbum")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" || req.AlbumID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/save_album", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func libraryLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSongsLimit
+	}
+	if limit > maxSongsLimit {
+		return maxSongsLimit
+	}
+	return limit
+}
+
+func (s *Service) GetLikedSongs(ctx context.Context, req structs.LibraryPageReq) (resp structs.GetLikedSongsResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetLikedSongs")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" {
+		resp.Error = "you must fill user id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	req.Limit = libraryLimit(req.Limit)
+
+	var respFromDB structs.GetLikedSongIDsResp
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/liked_songs", &respFromDB)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if respFromDB.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", respFromDB.Error))
+		resp.Error = respFromDB.Error
+		return resp, downstreamError(resp.Error)
+	}
+
+	resp.NextCursor = respFromDB.NextCursor
+	resp.Songs = make([]structs.LikedSong, 0, len(respFromDB.Songs))
+	for _, liked := range respFromDB.Songs {
+		resp.Songs = append(resp.Songs, structs.LikedSong{Song: s.songOrID(liked.SongID), LikedAt: liked.LikedAt})
+	}
+	return resp, nil
+}
+
+func (s *Service) GetSavedAlbums(ctx context.Context, req structs.LibraryPageReq) (resp structs.GetSavedAlbumsResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetSavedAlbums")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" {
+		resp.Error = "you must fill user id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	req.Limit = libraryLimit(req.Limit)
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/saved_albums", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) AreSongsLiked(ctx context.Context, req structs.AreSongsLikedReq) (resp structs.AreSongsLikedResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AreSongsLiked")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" || len(req.SongIDs) == 0 {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if len(req.SongIDs) > maxLikedCheck {
+		resp.Error = fmt.Sprintf("max %d songs per request", maxLikedCheck)
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/are_songs_liked", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+	if len(resp.Liked) != len(req.SongIDs) {
+		s.log(ctx).Error("db returned wrong number of songs", zap.Int("got", len(resp.Liked)), zap.Int("want", len(req.SongIDs)))
+		resp.Liked = nil
+		resp.Error = "wrong answer from db"
+		return resp, apperr.New(apperr.Internal, resp.Error)
+	}
+
+	return
+}
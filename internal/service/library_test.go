@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+func TestLikeSong(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      structs.LikeSongReq
+		wantCode apperr.Code
+	}{
+		{name: "like", req: structs.LikeSongReq{UserID: "u1", SongID: "1", Like: true}},
+		{name: "unlike", req: structs.LikeSongReq{UserID: "u1", SongID: "1"}},
+		{name: "no song", req: structs.LikeSongReq{UserID: "u1", Like: true}, wantCode: apperr.Validation},
+		{name: "no user", req: structs.LikeSongReq{SongID: "1", Like: true}, wantCode: apperr.Validation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent structs.LikeSongReq
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/like_song": func(body []byte) interface{} {
+					decode(t, body, &sent)
+					return structs.LikeSongResp{OK: true}
+				},
+			})
+
+			_, err := s.LikeSong(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				if f.count("/api/v1/like_song") != 0 {
+					t.Error("invalid like is sent to db")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LikeSong() error = %v", err)
+			}
+			if sent != tt.req {
+				t.Errorf("sent %+v, want %+v", sent, tt.req)
+			}
+		})
+	}
+}
+
+func TestGetLikedSongs(t *testing.T) {
+	likedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var sent structs.LibraryPageReq
+	s, _ := newTestService(t, map[string]route{
+		"/api/v1/liked_songs": func(body []byte) interface{} {
+			decode(t, body, &sent)
+			return structs.GetLikedSongIDsResp{NextCursor: "c2", Songs: []structs.LikedSongID{
+				{SongID: "1", LikedAt: likedAt},
+				{SongID: "deleted", LikedAt: likedAt},
+			}}
+		},
+	})
+	s.index.Add(globalStructs.Song{ID: "1", Name: "Help"})
+
+	resp, err := s.GetLikedSongs(context.Background(), structs.LibraryPageReq{UserID: "u1", Cursor: "c1", Limit: 10000})
+	if err != nil {
+		t.Fatalf("GetLikedSongs() error = %v", err)
+	}
+	if want := (structs.LibraryPageReq{UserID: "u1", Cursor: "c1", Limit: maxSongsLimit}); sent != want {
+		t.Errorf("sent %+v, want %+v", sent, want)
+	}
+	// songs missing in catalog keep their id, so client can still unlike them
+	want := structs.GetLikedSongsResp{NextCursor: "c2", Songs: []structs.LikedSong{
+		{Song: globalStructs.Song{ID: "1", Name: "Help"}, LikedAt: likedAt},
+		{Song: globalStructs.Song{ID: "deleted"}, LikedAt: likedAt},
+	}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("GetLikedSongs() = %+v, want %+v", resp, want)
+	}
+}
+
+func TestAreSongsLiked(t *testing.T) {
+	tooMany := make([]string, maxLikedCheck+1)
+	for i := range tooMany {
+		tooMany[i] = "1"
+	}
+
+	tests := []struct {
+		name     string
+		req      structs.AreSongsLikedReq
+		liked    []bool
+		want     []bool
+		wantCode apperr.Code
+	}{
+		{name: "checked", req: structs.AreSongsLikedReq{UserID: "u1", SongIDs: []string{"1", "2"}}, liked: []bool{false, true}, want: []bool{false, true}},
+		{name: "no songs", req: structs.AreSongsLikedReq{UserID: "u1"}, wantCode: apperr.Validation},
+		{name: "too many songs", req: structs.AreSongsLikedReq{UserID: "u1", SongIDs: tooMany}, wantCode: apperr.Validation},
+		// answer that does not match songs would put hearts on wrong songs
+		{name: "wrong answer", req: structs.AreSongsLikedReq{UserID: "u1", SongIDs: []string{"1", "2"}}, liked: []bool{true}, wantCode: apperr.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, map[string]route{
+				"/api/v1/are_songs_liked": answer(structs.AreSongsLikedResp{Liked: tt.liked}),
+			})
+
+			resp, err := s.AreSongsLiked(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				if resp.Liked != nil {
+					t.Errorf("liked = %v on error", resp.Liked)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AreSongsLiked() error = %v", err)
+			}
+			if !reflect.DeepEqual(resp.Liked, tt.want) {
+				t.Errorf("liked = %v, want %v", resp.Liked, tt.want)
+			}
+		})
+	}
+}
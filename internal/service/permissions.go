@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+// playlist visibilities
+const (
+	// VisibilityPrivate playlist is seen only by owner and users it is shared with
+	VisibilityPrivate = "private"
+	// VisibilityPublic playlist is seen by everyone, changed by owner and editors
+	VisibilityPublic = "public"
+	// VisibilityCollaborative playlist is seen by everyone, changed by owner, editors and followers
+	VisibilityCollaborative = "collaborative"
+)
+
+// roles playlist can be shared with
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+)
+
+var errPlaylistForbidden = apperr.New(apperr.Forbidden, "you dont have access to this playlist")
+
+func canViewPlaylist(access structs.PlaylistAccess) bool {
+	if access.UserID != "" && access.UserID == access.OwnerID {
+		return true
+	}
+	switch access.Visibility {
+	case VisibilityPublic, VisibilityCollaborative:
+		return true
+	}
+	return access.Role == RoleViewer || access.Role == RoleEditor
+}
+
+func canEditPlaylist(access structs.PlaylistAccess) bool {
+	if access.UserID == "" {
+		return false
+	}
+	if access.UserID == access.OwnerID || access.Role == RoleEditor {
+		return true
+	}
+	return access.Visibility == VisibilityCollaborative && access.Following && access.Role != RoleViewer
+}
+
+func (s *Service) getPlaylistAccess(ctx context.Context, playlistID, userID string) (structs.PlaylistAccess, error) {
+	var resp structs.GetPlaylistAccessResp
+	req := structs.GetPlaylistAccessReq{PlaylistID: playlistID, UserID: userID}
+	err := s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/playlist_access", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		return resp.Access, err
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp.Access, downstreamError(resp.Error)
+	}
+	// db may leave user id empty when user has no role
+	resp.Access.UserID = userID
+	return resp.Access, nil
+}
+
+// authorizePlaylist checks that user can view or edit playlist and returns playlist owner id.
+// Db only knows owners, so requests of editors are sent to db on behalf of the owner.
+func (s *Service) authorizePlaylist(ctx context.Context, playlistID, userID string, edit bool) (string, error) {
+	access, err := s.getPlaylistAccess(ctx, playlistID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	allowed := canViewPlaylist(access)
+	if edit {
+		allowed = canEditPlaylist(access)
+	}
+	if !allowed {
+		s.log(ctx).Warn("playlist access denied", zap.String("playlist_id", playlistID), zap.String("user_id", userID), zap.Bool("edit", edit))
+		return "", errPlaylistForbidden
+	}
+	return access.OwnerID, nil
+}
+
+func (s *Service) SetPlaylistVisibility(ctx context.Context, req structs.SetPlaylistVisibilityReq) (resp structs.SetPlaylistVisibilityResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SetPlaylistVisibility")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	switch req.Visibility {
+	case VisibilityPrivate, VisibilityPublic, VisibilityCollaborative:
+	default:
+		resp.Error = "visibility should be private, public or collaborative"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/set_playlist_visibility", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) SharePlaylist(ctx context.Context, req structs.SharePlaylistReq) (resp structs.SharePlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SharePlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" || req.TargetUserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Role != "" && req.Role != RoleViewer && req.Role != RoleEditor {
+		resp.Error = "role should be viewer, editor or empty"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.TargetUserID == req.UserID {
+		resp.Error = "cant share playlist with yourself"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	// only owner manages sharing, db checks UserID is the owner
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/share_playlist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
+
+func (s *Service) FollowPlaylist(ctx context.Context, req structs.FollowPlaylistReq) (resp structs.FollowPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.FollowPlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Follow {
+		if _, err = s.authorizePlaylist(ctx, req.PlaylistID, req.UserID, false); err != nil {
+			resp.Error = err.Error()
+			return
+		}
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/follow_playlist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return
+}
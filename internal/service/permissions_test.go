@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+func TestPlaylistPermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		access   structs.PlaylistAccess
+		wantView bool
+		wantEdit bool
+	}{
+		{"owner of private", structs.PlaylistAccess{OwnerID: "u1", UserID: "u1", Visibility: VisibilityPrivate}, true, true},
+		{"stranger to private", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityPrivate}, false, false},
+		{"viewer of private", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityPrivate, Role: RoleViewer}, true, false},
+		{"editor of private", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityPrivate, Role: RoleEditor}, true, true},
+		{"stranger to public", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityPublic}, true, false},
+		{"follower of public", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityPublic, Following: true}, true, false},
+		{"stranger to collaborative", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityCollaborative}, true, false},
+		{"follower of collaborative", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityCollaborative, Following: true}, true, true},
+		{"viewer following collaborative", structs.PlaylistAccess{OwnerID: "u1", UserID: "u2", Visibility: VisibilityCollaborative, Following: true, Role: RoleViewer}, true, false},
+		{"anonymous to public", structs.PlaylistAccess{OwnerID: "u1", Visibility: VisibilityPublic}, true, false},
+		// playlist without owner is not owned by anonymous user
+		{"anonymous to ownerless", structs.PlaylistAccess{Visibility: VisibilityPrivate}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canViewPlaylist(tt.access); got != tt.wantView {
+				t.Errorf("canViewPlaylist() = %v, want %v", got, tt.wantView)
+			}
+			if got := canEditPlaylist(tt.access); got != tt.wantEdit {
+				t.Errorf("canEditPlaylist() = %v, want %v", got, tt.wantEdit)
+			}
+		})
+	}
+}
+
+func TestGetPlaylistAccess(t *testing.T) {
+	tests := []struct {
+		name     string
+		access   route
+		wantCode apperr.Code
+	}{
+		{name: "shared viewer", access: answer(structs.GetPlaylistAccessResp{Access: structs.PlaylistAccess{OwnerID: "u1", Role: RoleViewer}})},
+		{name: "private", access: answer(structs.GetPlaylistAccessResp{Access: structs.PlaylistAccess{OwnerID: "u1", Visibility: VisibilityPrivate}}), wantCode: apperr.Forbidden},
+		{name: "missing playlist", access: answer(structs.GetPlaylistAccessResp{Error: "mongo: no documents in result"}), wantCode: apperr.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asked structsDB.GetPlaylistReq
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/playlist_access": tt.access,
+				"/api/v1/get_playlist": func(body []byte) interface{} {
+					decode(t, body, &asked)
+					return structsDB.GetPlaylistResp{Playlist: globalStructs.Playlist{ID: "p1", UserID: "u1"}}
+				},
+			})
+
+			_, err := s.GetPlaylist(context.Background(), structsDB.GetPlaylistReq{PlaylistID: "p1", UserID: "u2"})
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				if f.count("/api/v1/get_playlist") != 0 {
+					t.Error("playlist is read without access")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPlaylist() error = %v", err)
+			}
+			// db only knows owners, so shared playlist is read on behalf of owner
+			if asked.UserID != "u1" {
+				t.Errorf("playlist is read as %q, want owner", asked.UserID)
+			}
+		})
+	}
+}
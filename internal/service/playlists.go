@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+	"go.uber.org/zap"
+)
+
+// maxPlaylistBatch is max number of songs added or removed in one call
+const maxPlaylistBatch = 200
+
+func (s *Service) UpdatePlaylist(ctx context.Context, req structs.UpdatePlaylistReq) (resp structs.UpdatePlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdatePlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" && req.Description == nil && len(req.Image) == 0 {
+		resp.Error = "nothing to update"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	// editors update playlist on behalf of owner like they change its songs
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, req.UserID, true)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	snapshot := s.snapshotPlaylist(ctx, req.PlaylistID, ownerID)
+	reqToDB := structs.UpdatePlaylistDBReq{
+		UserID:      ownerID,
+		PlaylistID:  req.PlaylistID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if len(req.Image) != 0 {
+		reqToDB.ArtworkID, err = s.storeArtwork(ctx, req.Image)
+		if err != nil {
+			resp.Error = err.Error()
+			return
+		}
+	}
+
+	err = s.client.SendRequest(ctx, reqToDB, "post", "http://localhost:8082/api/v1/update_playlist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", reqToDB))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.recordPlaylistVersion(ctx, snapshot, req.UserID, ActionUpdate, nil)
+	return
+}
+
+func (s *Service) MoveSongInPlaylist(ctx context.Context, req structs.MoveSongInPlaylistReq) (resp structs.MoveSongInPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.MoveSongInPlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" || req.SongID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Position < 0 {
+		resp.Error = "position should not be negative"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	userID := req.UserID
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, userID, true)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	req.UserID = ownerID
+
+	snapshot := s.snapshotPlaylist(ctx, req.PlaylistID, ownerID)
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/move_song_playlist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.recordPlaylistVersion(ctx, snapshot, userID, ActionMoveSong, []string{req.SongID})
+	return
+}
+
+func (s *Service) AddSongsToPlaylist(ctx context.Context, req structs.AddSongsToPlaylistReq) (resp structs.AddSongsToPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AddSongsToPlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	req.SongIDs, err = batchSongIDs(req.SongIDs)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	userID := req.UserID
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, userID, true)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	req.UserID = ownerID
+
+	snapshot := s.snapshotPlaylist(ctx, req.PlaylistID, ownerID)
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/add_songs_playlist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.recordPlaylistVersion(ctx, snapshot, userID, ActionAddSongs, req.SongIDs)
+	return
+}
+
+func (s *Service) RemoveSongsFromPlaylist(ctx context.Context, req structs.RemoveSongsFromPlaylistReq) (resp structs.RemoveSongsFromPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.RemoveSongsFromPlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.PlaylistID == "" || req.UserID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	req.SongIDs, err = batchSongIDs(req.SongIDs)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	userID := req.UserID
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, userID, true)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	req.UserID = ownerID
+
+	snapshot := s.snapshotPlaylist(ctx, req.PlaylistID, ownerID)
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/remove_songs_playlist", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.recordPlaylistVersion(ctx, snapshot, userID, ActionRemoveSongs, req.SongIDs)
+	return
+}
+
+// batchSongIDs validates song ids of batch request and removes duplicates keeping order
+func batchSongIDs(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, apperr.New(apperr.Validation, "you must fill song ids")
+	}
+	if len(ids) > maxPlaylistBatch {
+		return nil, apperr.New(apperr.Validation, fmt.Sprintf("max %d songs per request", maxPlaylistBatch))
+	}
+
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return nil, apperr.New(apperr.Validation, "song id is empty")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+func (s *Service) GetPlaylistTracks(ctx context.Context, req structsDB.GetPlaylistReq) (resp structs.GetPlaylistTracksResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetPlaylistTracks")
+	defer func() { tracing.End(span, err) }()
+
+	playlist, err := s.GetPlaylist(ctx, req)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	resp.Songs = make([]globalStructs.Song, 0, len(playlist.Playlist.SongIDs))
+	for _, id := range playlist.Playlist.SongIDs {
+		resp.Songs = append(resp.Songs, s.songOrID(id))
+	}
+	return resp, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+func TestUpdatePlaylist(t *testing.T) {
+	empty, name := "", "Road trip"
+	tests := []struct {
+		name   string
+		req    structs.UpdatePlaylistReq
+		access structs.PlaylistAccess
+		// wantDB is description sent to db as json, null leaves it untouched
+		wantDB   string
+		wantCode apperr.Code
+	}{
+		{
+			name:   "owner renames",
+			req:    structs.UpdatePlaylistReq{UserID: "u1", PlaylistID: "p1", Name: " " + name + " "},
+			access: structs.PlaylistAccess{OwnerID: "u1"},
+			wantDB: `{"user_id":"u1","playlist_id":"p1","name":"Road trip","description":null,"artwork_id":""}`,
+		},
+		{
+			name:   "editor clears description",
+			req:    structs.UpdatePlaylistReq{UserID: "u2", PlaylistID: "p1", Description: &empty},
+			access: structs.PlaylistAccess{OwnerID: "u1", Role: RoleEditor},
+			wantDB: `{"user_id":"u1","playlist_id":"p1","name":"","description":"","artwork_id":""}`,
+		},
+		{
+			name:     "viewer",
+			req:      structs.UpdatePlaylistReq{UserID: "u2", PlaylistID: "p1", Name: name},
+			access:   structs.PlaylistAccess{OwnerID: "u1", Visibility: VisibilityPublic, Role: RoleViewer},
+			wantCode: apperr.Forbidden,
+		},
+		{
+			name:     "nothing to update",
+			req:      structs.UpdatePlaylistReq{UserID: "u1", PlaylistID: "p1", Name: "  "},
+			wantCode: apperr.Validation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent string
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/playlist_access": answer(structs.GetPlaylistAccessResp{Access: tt.access}),
+				"/api/v1/get_playlist": answer(structsDB.GetPlaylistResp{Playlist: globalStructs.Playlist{
+					ID: "p1", Name: "Mix", SongIDs: []string{"1"},
+				}}),
+				"/api/v1/update_playlist": func(body []byte) interface{} {
+					sent = string(body)
+					return structs.UpdatePlaylistResp{OK: true}
+				},
+				"/api/v1/add_playlist_version": answer(structs.AddPlaylistVersionResp{Version: 1}),
+			})
+
+			_, err := s.UpdatePlaylist(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				// access is checked before playlist is read or changed
+				for _, path := range []string{"/api/v1/get_playlist", "/api/v1/update_playlist"} {
+					if f.count(path) != 0 {
+						t.Errorf("%s called on failed update", path)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdatePlaylist() error = %v", err)
+			}
+			if sent != tt.wantDB {
+				t.Errorf("sent to db %s, want %s", sent, tt.wantDB)
+			}
+			if f.count("/api/v1/add_playlist_version") != 1 {
+				t.Error("update is not recorded to history")
+			}
+		})
+	}
+}
+
+func TestBatchSongIDs(t *testing.T) {
+	tooMany := make([]string, maxPlaylistBatch+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprint(i)
+	}
+
+	tests := []struct {
+		name    string
+		ids     []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "keeps order", ids: []string{"3", "1", "2"}, want: []string{"3", "1", "2"}},
+		{name: "drops duplicates", ids: []string{"1", "2", "1", "2", "3"}, want: []string{"1", "2", "3"}},
+		{name: "empty batch", wantErr: true},
+		{name: "empty id", ids: []string{"1", ""}, wantErr: true},
+		{name: "too many", ids: tooMany, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := batchSongIDs(tt.ids)
+			if tt.wantErr {
+				if apperr.CodeOf(err) != apperr.Validation {
+					t.Fatalf("err = %v, want validation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batchSongIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPlaylistSongChanges checks editors change songs on behalf of owner and every change is recorded
+func TestPlaylistSongChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		change  func(s *Service) error
+		want    string
+		wantErr apperr.Code
+	}{
+		{
+			name: "move",
+			path: "/api/v1/move_song_playlist",
+			change: func(s *Service) error {
+				_, err := s.MoveSongInPlaylist(context.Background(), structs.MoveSongInPlaylistReq{UserID: "u2", PlaylistID: "p1", SongID: "1", Position: 0})
+				return err
+			},
+			want: `{"user_id":"u1","playlist_id":"p1","song_id":"1","position":0}`,
+		},
+		{
+			name: "move to negative position",
+			path: "/api/v1/move_song_playlist",
+			change: func(s *Service) error {
+				_, err := s.MoveSongInPlaylist(context.Background(), structs.MoveSongInPlaylistReq{UserID: "u2", PlaylistID: "p1", SongID: "1", Position: -1})
+				return err
+			},
+			wantErr: apperr.Validation,
+		},
+		{
+			name: "add batch",
+			path: "/api/v1/add_songs_playlist",
+			change: func(s *Service) error {
+				_, err := s.AddSongsToPlaylist(context.Background(), structs.AddSongsToPlaylistReq{UserID: "u2", PlaylistID: "p1", SongIDs: []string{"2", "3", "2"}})
+				return err
+			},
+			want: `{"user_id":"u1","playlist_id":"p1","song_ids":["2","3"]}`,
+		},
+		{
+			name: "remove batch",
+			path: "/api/v1/remove_songs_playlist",
+			change: func(s *Service) error {
+				_, err := s.RemoveSongsFromPlaylist(context.Background(), structs.RemoveSongsFromPlaylistReq{UserID: "u2", PlaylistID: "p1", SongIDs: []string{"1"}})
+				return err
+			},
+			want: `{"user_id":"u1","playlist_id":"p1","song_ids":["1"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent string
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/playlist_access": answer(structs.GetPlaylistAccessResp{Access: structs.PlaylistAccess{OwnerID: "u1", Role: RoleEditor}}),
+				"/api/v1/get_playlist": answer(structsDB.GetPlaylistResp{Playlist: globalStructs.Playlist{
+					ID: "p1", Name: "Mix", SongIDs: []string{"2", "1"},
+				}}),
+				tt.path: func(body []byte) interface{} {
+					sent = string(body)
+					return map[string]bool{"ok": true}
+				},
+				"/api/v1/add_playlist_version": answer(structs.AddPlaylistVersionResp{Version: 1}),
+			})
+
+			err := tt.change(s)
+			if tt.wantErr != "" {
+				if apperr.CodeOf(err) != tt.wantErr {
+					t.Fatalf("err = %v, want code %s", err, tt.wantErr)
+				}
+				if f.count(tt.path) != 0 {
+					t.Errorf("%s called on invalid change", tt.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent != tt.want {
+				t.Errorf("sent to db %s, want %s", sent, tt.want)
+			}
+			if f.count("/api/v1/add_playlist_version") != 1 {
+				t.Error("change is not recorded to history")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/plays"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+// play event types, PlayEventInferred is made by server from segment fetches
+const (
+	PlayEventStart    = "start"
+	PlayEventProgress = "progress"
+	PlayEventComplete = "complete"
+	PlayEventSkip     = "skip"
+	PlayEventInferred = "play"
+)
+
+const (
+	PlaySourceClient   = "client"
+	PlaySourceSegments = "segments"
+)
+
+// anonymousListenerPrefix marks tracker listeners without user id, they are keyed by ip
+const anonymousListenerPrefix = "anon:"
+
+func (s *Service) RecordPlayEvent(ctx context.Context, req structs.PlayEventReq) (resp structs.PlayEventResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.RecordPlayEvent")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" || req.SongID == "" {
+		resp.Error = "you must fill all ids"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.PositionMs < 0 {
+		resp.Error = "position should not be negative"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	event := structs.PlayEvent{
+		UserID:     req.UserID,
+		SongID:     req.SongID,
+		Type:       req.Type,
+		PositionMs: req.PositionMs,
+		Source:     PlaySourceClient,
+		CreatedAt:  time.Now(),
+	}
+	switch req.Type {
+	case PlayEventStart:
+		// client reports this playback itself, dont infer it from segments
+		s.plays.Reported(req.UserID, req.SongID)
+	case PlayEventProgress:
+	case PlayEventComplete:
+		event.Counted = true
+	case PlayEventSkip:
+		event.Counted = time.Duration(req.PositionMs)*time.Millisecond >= plays.MinPlayDuration
+	default:
+		resp.Error = "type should be start, progress, complete or skip"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	if err = s.savePlayEvent(ctx, event); err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	resp.OK = true
+	return resp, nil
+}
+
+// TrackSegmentFetch feeds play tracker with segment served to user,
+// listeners without user id are told apart by ip
+func (s *Service) TrackSegmentFetch(ctx context.Context, userID, remoteAddr, segmentID string) {
+	_, span := tracer.Start(ctx, "Service.TrackSegmentFetch")
+	defer span.End()
+
+	listener := userID
+	if listener == "" {
+		host, _, err := net.SplitHostPort(remoteAddr)
+		if err != nil {
+			host = remoteAddr
+		}
+		listener = anonymousListenerPrefix + host
+	}
+	s.plays.SegmentFetched(listener, segmentID)
+}
+
+// inferredPlay is called by tracker, it must not block segment serving
+func (s *Service) inferredPlay(listener, songID string) {
+	event := structs.PlayEvent{
+		UserID:    listener,
+		SongID:    songID,
+		Type:      PlayEventInferred,
+		Source:    PlaySourceSegments,
+		Counted:   true,
+		CreatedAt: time.Now(),
+	}
+	if strings.HasPrefix(listener, anonymousListenerPrefix) {
+		event.UserID = ""
+	}
+
+	started := s.goJob(func() {
+		if err := s.savePlayEvent(s.ctx, event); err != nil {
+			s.logger.Error("error saving inferred play", zap.Error(err), zap.Any("event", event))
+		}
+	})
+	if !started {
+		s.logger.Warn("service is closed, inferred play is dropped", zap.Any("event", event))
+	}
+}
+
+func (s *Service) savePlayEvent(ctx context.Context, event structs.PlayEvent) error {
+	var resp structs.PlayEventResp
+	err := s.client.SendRequest(ctx, event, "post", "http://localhost:8082/api/v1/add_play_event", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("event", event))
+		return err
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return downstreamError(resp.Error)
+	}
+
+	s.addLiveToCharts(event)
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/charts"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	"go.uber.org/zap"
+)
+
+const (
+	recommendInterval = time.Hour
+	// recommendHistory is how old plays are used for recommendations
+	recommendHistory  = 182 * 24 * time.Hour
+	defaultRadioLimit = 30
+	maxRadioLimit     = 100
+)
+
+// runRecommendations rebuilds recommendations every recommendInterval until service is closed
+func (s *Service) runRecommendations() {
+	s.buildRecommendations()
+	ticker := time.NewTicker(recommendInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.buildRecommendations()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// buildRecommendations uses every playlist and every user day of listening as basket of related songs.
+// If db cant give either of them, previous model is kept.
+func (s *Service) buildRecommendations() {
+	ctx, span := tracer.Start(s.ctx, "Service.buildRecommendations")
+	defer span.End()
+
+	started := time.Now()
+	var baskets [][]string
+
+	var playlists structsDB.GetUserAllPlaylistsResp
+	err := s.client.SendRequest(ctx, nil, "get", "http://localhost:8082/api/v1/all_playlists", &playlists)
+	if err != nil || playlists.Error != "" {
+		s.log(ctx).Error("error getting playlists for recommendations", zap.Error(err), zap.Any("error", playlists.Error))
+		return
+	}
+	for _, playlist := range playlists.Playlists {
+		baskets = append(baskets, playlist.SongIDs)
+	}
+
+	var history structs.GetPlayEventsResp
+	err = s.client.SendRequest(ctx, structs.GetPlayEventsReq{Since: started.Add(-recommendHistory)}, "post", "http://localhost:8082/api/v1/play_events", &history)
+	if err != nil || history.Error != "" {
+		s.log(ctx).Error("error getting play events for recommendations", zap.Error(err), zap.Any("error", history.Error))
+		return
+	}
+	type userDay struct {
+		userID string
+		day    string
+	}
+	sessions := make(map[userDay][]string)
+	for _, event := range history.Events {
+		if !event.Counted || event.UserID == "" {
+			continue
+		}
+		key := userDay{event.UserID, event.CreatedAt.UTC().Format("2006-01-02")}
+		sessions[key] = append(sessions[key], event.SongID)
+	}
+	for _, songs := range sessions {
+		baskets = append(baskets, songs)
+	}
+
+	s.recommender.Build(baskets)
+	s.log(ctx).Info("recommendations built", zap.Int("baskets", len(baskets)), zap.Duration("took", time.Since(started)))
+}
+
+func (s *Service) GetRadio(ctx context.Context, req structs.RadioReq) (resp structs.RadioResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetRadio")
+	defer func() { tracing.End(span, err) }()
+
+	seeds := 0
+	for _, seed := range []string{req.SongID, req.Artist, req.PlaylistID} {
+		if seed != "" {
+			seeds++
+		}
+	}
+	if seeds != 1 {
+		resp.Error = "you must fill one of song id, artist or playlist id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultRadioLimit
+	}
+	if req.Limit > maxRadioLimit {
+		req.Limit = maxRadioLimit
+	}
+
+	var seedIDs []string
+	switch {
+	case req.SongID != "":
+		seedIDs = []string{req.SongID}
+	case req.Artist != "":
+		artist := s.artists.key(req.Artist)
+		for _, song := range s.index.Songs() {
+			if s.artists.key(song.Band) == artist {
+				seedIDs = append(seedIDs, song.ID)
+			}
+		}
+	default:
+		playlist, err := s.GetPlaylist(ctx, structsDB.GetPlaylistReq{PlaylistID: req.PlaylistID, UserID: req.UserID})
+		if err != nil {
+			resp.Error = err.Error()
+			return resp, err
+		}
+		seedIDs = playlist.Playlist.SongIDs
+	}
+	if len(seedIDs) == 0 {
+		resp.Error = "seed has no songs"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	used := make(map[string]bool, len(seedIDs))
+	for _, id := range seedIDs {
+		used[id] = true
+	}
+	add := func(id string) {
+		if used[id] || len(resp.Songs) >= req.Limit {
+			return
+		}
+		// songs deleted since last build are not in catalog anymore
+		song, ok := s.index.Get(id)
+		if !ok {
+			return
+		}
+		used[id] = true
+		resp.Songs = append(resp.Songs, song)
+	}
+
+	for _, scored := range s.recommender.Recommend(seedIDs, req.Limit*2) {
+		add(scored.SongID)
+	}
+	// not enough data for seed yet, fill with popular songs
+	if len(resp.Songs) < req.Limit {
+		popular, _ := s.charts.Chart(charts.WindowMonth, req.Limit*2)
+		for _, count := range popular {
+			add(count.Key)
+		}
+	}
+
+	return resp, nil
+}
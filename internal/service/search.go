@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/search"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+const (
+	// searchIndexRefresh is how often index is reloaded, so songs changed past this service show up
+	searchIndexRefresh = 10 * time.Minute
+	// first load is retried with backoff growing up to searchIndexMaxRetry
+	searchIndexMinRetry = time.Second
+	searchIndexMaxRetry = time.Minute
+)
+
+// waitSearchIndex loads search index, retrying until db answers. It returns false if service was closed first.
+func (s *Service) waitSearchIndex() bool {
+	retry := searchIndexMinRetry
+	for s.loadSearchIndex() != nil {
+		select {
+		case <-time.After(retry):
+		case <-s.ctx.Done():
+			return false
+		}
+		if retry *= 2; retry > searchIndexMaxRetry {
+			retry = searchIndexMaxRetry
+		}
+	}
+	return true
+}
+
+// refreshSearchIndex reloads search index until service is closed, failed reload keeps old index
+func (s *Service) refreshSearchIndex() {
+	ticker := time.NewTicker(searchIndexRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.loadSearchIndex()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// loadSearchIndex fills search index with whole songs catalog from db and reloads artists bands are grouped by
+func (s *Service) loadSearchIndex() error {
+	ctx, span := tracer.Start(s.ctx, "Service.loadSearchIndex")
+	defer span.End()
+
+	s.loadArtists(ctx)
+	resp, err := s.GetAllSongs(ctx)
+	if err != nil {
+		s.log(ctx).Error("error loading songs to search index", zap.Error(err))
+		return err
+	}
+	s.index.Reset(resp.Songs)
+	s.log(ctx).Info("search index loaded", zap.Int("songs", s.index.Len()))
+	return nil
+}
+
+func (s *Service) Search(ctx context.Context, req structs.SearchReq) (resp structs.SearchResp, err error) {
+	_, span := tracer.Start(ctx, "Service.Search")
+	defer func() { tracing.End(span, err) }()
+
+	if req.Query == "" {
+		resp.Error = "query is empty"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultSearchLimit
+	}
+	if req.Limit > maxSearchLimit {
+		req.Limit = maxSearchLimit
+	}
+
+	artists := make(map[string]bool)
+	albums := make(map[structs.SearchAlbum]bool)
+	// artists and albums are taken from all hits, songs are limited later
+	for _, hit := range s.index.Search(req.Query, 0) {
+		if len(resp.Songs) < req.Limit {
+			resp.Songs = append(resp.Songs, hit.Song)
+		}
+		// bands of the same artist are listed once under artist name
+		band := s.artists.canonical(hit.Song.Band)
+		if hit.Fields&search.FieldBand != 0 && !artists[band] && len(resp.Artists) < req.Limit {
+			artists[band] = true
+			resp.Artists = append(resp.Artists, band)
+		}
+		album := structs.SearchAlbum{Name: hit.Song.Album, Band: band}
+		if hit.Fields&search.FieldAlbum != 0 && !albums[album] && len(resp.Albums) < req.Limit {
+			albums[album] = true
+			resp.Albums = append(resp.Albums, album)
+		}
+	}
+
+	return resp, nil
+}
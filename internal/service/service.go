@@ -2,80 +2,252 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/floyernick/fleep-go"
 	structs2 "github.com/supperdoggy/spotify-web-project/spotify-auth/shared/structs"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/charts"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/client"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/logging"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/metrics"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/plays"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/recommend"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/search"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
 	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
 	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
 	dbStructs "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
 	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
 	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
 	"github.com/u2takey/go-utils/rand"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 	"gopkg.in/night-codes/types.v1"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 type IService interface {
-	CreateNewSong(req structs.CreateNewSongReq) error
-	GetAllSongs() (resp structsDB.GetAllSongsResp, err error)
-	GetSegment(id string) ([]byte, error)
-	Register(req structs2.RegisterReq) (resp structs2.NewTokenResp, err error)
-	Login(req structs2.LoginReq) (resp structs2.LoginResp, err error)
-	RemoveSongFromPlaylist(req structsDB.RemoveSongFromUserPlaylistReq) (resp structsDB.RemoveSongFromUserPlaylistResp, err error)
-	GetUserPlaylists(req structsDB.GetUserAllPlaylistsReq) (resp structsDB.GetUserAllPlaylistsResp, err error)
-	GetPlaylist(req structsDB.GetPlaylistReq) (resp structsDB.GetPlaylistResp, err error)
-	NewPlaylist(req structsDB.NewPlaylistReq) (resp structsDB.NewPlaylistResp, err error)
-	DeletePlaylist(req structsDB.DeleteUserPlaylistReq) (resp structsDB.DeleteUserPlaylistResp, err error)
-	AddSongToPlaylist(req structsDB.AddSongToUserPlaylistReq) (resp structsDB.AddSongToUserPlaylistResp, err error)
+	CreateNewSong(ctx context.Context, req structs.CreateNewSongReq) (song globalStructs.Song, err error)
+	GetAllSongs(ctx context.Context) (resp structsDB.GetAllSongsResp, err error)
+	GetSongs(ctx context.Context, req structs.GetSongsReq) (resp structs.GetSongsResp, err error)
+	GetSong(ctx context.Context, req structs.GetSongReq) (resp structs.GetSongResp, err error)
+	GetSegment(ctx context.Context, id string) ([]byte, error)
+	Register(ctx context.Context, req structs2.RegisterReq) (resp structs2.NewTokenResp, err error)
+	Login(ctx context.Context, req structs2.LoginReq) (resp structs2.LoginResp, err error)
+	RemoveSongFromPlaylist(ctx context.Context, req structsDB.RemoveSongFromUserPlaylistReq) (resp structsDB.RemoveSongFromUserPlaylistResp, err error)
+	GetUserPlaylists(ctx context.Context, req structsDB.GetUserAllPlaylistsReq) (resp structsDB.GetUserAllPlaylistsResp, err error)
+	GetPlaylist(ctx context.Context, req structsDB.GetPlaylistReq) (resp structsDB.GetPlaylistResp, err error)
+	NewPlaylist(ctx context.Context, req structsDB.NewPlaylistReq) (resp structsDB.NewPlaylistResp, err error)
+	DeletePlaylist(ctx context.Context, req structsDB.DeleteUserPlaylistReq) (resp structsDB.DeleteUserPlaylistResp, err error)
+	AddSongToPlaylist(ctx context.Context, req structsDB.AddSongToUserPlaylistReq) (resp structsDB.AddSongToUserPlaylistResp, err error)
+	UpdateSong(ctx context.Context, req structs.UpdateSongReq) (resp structs.UpdateSongResp, err error)
+	PatchSong(ctx context.Context, req structs.PatchSongReq) (resp structs.UpdateSongResp, err error)
+	DeleteSong(ctx context.Context, req structs.DeleteSongReq) (resp structs.DeleteSongResp, err error)
+	Search(ctx context.Context, req structs.SearchReq) (resp structs.SearchResp, err error)
+	NewArtist(ctx context.Context, req structs.NewArtistReq) (resp structs.NewArtistResp, err error)
+	GetArtist(ctx context.Context, req structs.GetArtistReq) (resp structs.GetArtistResp, err error)
+	NewAlbum(ctx context.Context, req structs.NewAlbumReq) (resp structs.NewAlbumResp, err error)
+	GetArtistAlbums(ctx context.Context, req structs.GetArtistAlbumsReq) (resp structs.GetArtistAlbumsResp, err error)
+	GetAlbumTracks(ctx context.Context, req structs.GetAlbumTracksReq) (resp structs.GetAlbumTracksResp, err error)
+	UploadArtwork(ctx context.Context, req structs.UploadArtworkReq) (resp structs.UploadArtworkResp, err error)
+	GetArtwork(ctx context.Context, id string) ([]byte, error)
+	UpdatePlaylist(ctx context.Context, req structs.UpdatePlaylistReq) (resp structs.UpdatePlaylistResp, err error)
+	MoveSongInPlaylist(ctx context.Context, req structs.MoveSongInPlaylistReq) (resp structs.MoveSongInPlaylistResp, err error)
+	AddSongsToPlaylist(ctx context.Context, req structs.AddSongsToPlaylistReq) (resp structs.AddSongsToPlaylistResp, err error)
+	RemoveSongsFromPlaylist(ctx context.Context, req structs.RemoveSongsFromPlaylistReq) (resp structs.RemoveSongsFromPlaylistResp, err error)
+	SetPlaylistVisibility(ctx context.Context, req structs.SetPlaylistVisibilityReq) (resp structs.SetPlaylistVisibilityResp, err error)
+	SharePlaylist(ctx context.Context, req structs.SharePlaylistReq) (resp structs.SharePlaylistResp, err error)
+	FollowPlaylist(ctx context.Context, req structs.FollowPlaylistReq) (resp structs.FollowPlaylistResp, err error)
+	GetPlaylistHistory(ctx context.Context, req structs.GetPlaylistHistoryReq) (resp structs.GetPlaylistHistoryResp, err error)
+	RestorePlaylistVersion(ctx context.Context, req structs.RestorePlaylistVersionReq) (resp structs.RestorePlaylistVersionResp, err error)
+	GetPlaylistTracks(ctx context.Context, req structsDB.GetPlaylistReq) (resp structs.GetPlaylistTracksResp, err error)
+	GetPlaylistStream(ctx context.Context, req structsDB.GetPlaylistReq) ([]byte, error)
+	ExportPlaylist(ctx context.Context, req structs.ExportPlaylistReq) ([]byte, error)
+	ImportPlaylist(ctx context.Context, req structs.ImportPlaylistReq) (resp structs.ImportPlaylistResp, err error)
+	RecordPlayEvent(ctx context.Context, req structs.PlayEventReq) (resp structs.PlayEventResp, err error)
+	TrackSegmentFetch(ctx context.Context, userID, remoteAddr, segmentID string)
+	GetRecentlyPlayed(ctx context.Context, req structs.RecentlyPlayedReq) (resp structs.RecentlyPlayedResp, err error)
+	GetTopTracks(ctx context.Context, req structs.TopReq) (resp structs.TopTracksResp, err error)
+	GetTopArtists(ctx context.Context, req structs.TopReq) (resp structs.TopArtistsResp, err error)
+	GetCharts(ctx context.Context, req structs.ChartsReq) (resp structs.ChartsResp, err error)
+	LikeSong(ctx context.Context, req structs.LikeSongReq) (resp structs.LikeSongResp, err error)
+	SaveAlbum(ctx context.Context, req structs.SaveAlbumReq) (resp structs.SaveAlbumResp, err error)
+	GetLikedSongs(ctx context.Context, req structs.LibraryPageReq) (resp structs.GetLikedSongsResp, err error)
+	GetSavedAlbums(ctx context.Context, req structs.LibraryPageReq) (resp structs.GetSavedAlbumsResp, err error)
+	AreSongsLiked(ctx context.Context, req structs.AreSongsLikedReq) (resp structs.AreSongsLikedResp, err error)
+	GetRadio(ctx context.Context, req structs.RadioReq) (resp structs.RadioResp, err error)
+	DownstreamStates(ctx context.Context) map[string]string
+	CheckReadiness(ctx context.Context) (resp structs.ReadinessResp)
+	Close(ctx context.Context) error
 }
 
+const (
+	defaultSongsLimit = 50
+	maxSongsLimit     = 500
+)
+
+// songsSortFields are fields db can sort songs catalog by
+var songsSortFields = map[string]bool{
+	"name":         true,
+	"band":         true,
+	"album":        true,
+	"release_date": true,
+	"upload_time":  true,
+}
+
+var tracer = otel.Tracer("github.com/supperdoggy/spotify-web-project/spotify-back/internal/service")
+
 type Service struct {
 	logger *zap.Logger
+	index  *search.Index
+	plays  *plays.Tracker
+	// artists groups bands of songs under canonical artist names
+	artists *artistNames
+	charts  *charts.Aggregator
+
+	recommender *recommend.Engine
+	client      *client.Client
+	// probe checks readiness of db and auth without client retries and circuit breaker
+	probe *http.Client
+
+	// chartsCutoff splits play events between loadCharts and live updates. It is
+	// truncated to milliseconds db keeps, so every event falls on one side only.
+	chartsCutoff time.Time
+
+	// ctx is cancelled by Close to stop background work
+	ctx    context.Context
+	cancel context.CancelFunc
+	jobsMu sync.Mutex
+	closed bool
+	jobs   sync.WaitGroup
 }
 
 func NewService(l *zap.Logger) IService {
-	return &Service{logger: l}
+	s := newService(l, client.New(l, downstreamPolicies))
+	s.goJob(func() {
+		// charts need index to know band of played songs
+		if !s.waitSearchIndex() {
+			return
+		}
+		s.goJob(s.refreshSearchIndex)
+		s.loadCharts()
+		s.runRecommendations()
+	})
+	return s
 }
 
-func (s *Service) CreateNewSong(req structs.CreateNewSongReq) error {
+// newService creates service without starting background jobs
+func newService(l *zap.Logger, c *client.Client) *Service {
+	s := &Service{
+		logger:       l,
+		index:        search.NewIndex(),
+		artists:      newArtistNames(),
+		charts:       charts.NewAggregator(),
+		recommender:  recommend.NewEngine(),
+		client:       c,
+		probe:        &http.Client{},
+		chartsCutoff: time.Now().Truncate(time.Millisecond),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.plays = plays.NewTracker(s.inferredPlay)
+	return s
+}
+
+// goJob runs background job Close waits for, jobs started after Close are dropped
+func (s *Service) goJob(job func()) bool {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	if s.closed {
+		return false
+	}
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		job()
+	}()
+	return true
+}
+
+// Close stops background work and waits for jobs in flight until ctx is done
+func (s *Service) Close(ctx context.Context) error {
+	s.jobsMu.Lock()
+	s.closed = true
+	s.jobsMu.Unlock()
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// log returns logger of request ctx belongs to
+func (s *Service) log(ctx context.Context) *zap.Logger {
+	return logging.Logger(ctx, s.logger)
+}
+
+func (s *Service) CreateNewSong(ctx context.Context, req structs.CreateNewSongReq) (song globalStructs.Song, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateNewSong")
+	defer func() { tracing.End(span, err) }()
+
 	if req.SongData == nil || len(req.SongData) == 0 || req.Name == "" || req.Band == "" || req.Album == "" {
-		return errors.New("fill all the fields")
+		return song, apperr.New(apperr.Validation, "fill all the fields")
 	}
 
 	info, err := fleep.GetInfo(req.SongData)
 	if err != nil {
-		return err
+		return song, err
 	}
 
 	if !info.IsAudio() {
-		return errors.New("file should be audio")
+		return song, apperr.New(apperr.Validation, "file should be audio")
 	}
 
 	fileName := types.String(time.Now().UnixNano())
+	defer func() {
+		if err := utils.RemoveUploadFiles(fileName); err != nil {
+			s.log(ctx).Error("error removing upload files", zap.Error(err))
+		}
+	}()
 	err = utils.CreateMP3File(fileName, req.SongData)
 	if err != nil {
-		s.logger.Error("error creating new mp3 file", zap.Error(err))
-		return err
+		s.log(ctx).Error("error creating new mp3 file", zap.Error(err))
+		return song, err
 	}
 
-	m3h8, ts, err := utils.ConvMp3ToM3U8(s.logger, fileName+".mp3", fileName)
+	started := time.Now()
+	m3h8, ts, err := utils.ConvMp3ToM3U8(ctx, s.log(ctx), fileName+".mp3", fileName)
+	metrics.TranscodeDuration.Observe(time.Since(started).Seconds())
 	if err != nil {
-		s.logger.Error("error converting mp3 to m3u8", zap.Error(err))
-		return err
+		metrics.TranscodeFailures.Inc()
+		s.log(ctx).Error("error converting mp3 to m3u8", zap.Error(err))
+		return song, err
 	}
 
-	song := globalStructs.Song{
+	song = globalStructs.Song{
 		ID:          fileName,
 		Name:        req.Name,
 		Album:       req.Album,
 		Band:        req.Band,
 		ReleaseDate: req.ReleaseDate,
-		Path:        fmt.Sprintf("http://localhost:8080/%s.m3u8", fileName),
+		Path:        fmt.Sprintf("http://localhost:8080/segments/%s.m3u8", fileName),
 	}
 
 	var respFromDB dbStructs.AddSegmentsResp
@@ -88,55 +260,160 @@ func (s *Service) CreateNewSong(req structs.CreateNewSongReq) error {
 
 	marshalled, err := json.Marshal(reqToDB)
 	if err != nil {
-		s.logger.Error("error marshaling req to db", zap.Error(err))
-		return err
+		s.log(ctx).Error("error marshaling req to db", zap.Error(err))
+		return song, err
 	}
 
 	buf := bytes.NewBuffer(marshalled)
 
-	resp, err := http.Post("http://localhost:8082/api/v1/addSegment", "application/json", buf)
+	resp, err := s.client.Post(ctx, "http://localhost:8082/api/v1/addSegment", "application/json", buf)
 	if err != nil {
-		s.logger.Error("error making req to db", zap.Error(err))
-		return err
+		s.log(ctx).Error("error making req to db", zap.Error(err))
+		return song, err
 	}
 
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
-		return err
+		s.log(ctx).Error("error reading body", zap.Error(err))
+		return song, err
 	}
 
 	err = json.Unmarshal(data, &respFromDB)
 	if err != nil {
-		s.logger.Error("error unmarshaling answer", zap.Error(err))
-		return err
+		s.log(ctx).Error("error unmarshaling answer", zap.Error(err))
+		return song, err
 	}
 	defer resp.Body.Close()
 
 	if !respFromDB.OK {
-		s.logger.Error("got error from db", zap.Any("error", respFromDB.Error))
-		return errors.New(respFromDB.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", respFromDB.Error))
+		return song, downstreamError(respFromDB.Error)
 	}
 
-	return nil
+	s.index.Add(song)
+	s.attachSongArtwork(ctx, song.ID, req.Artwork, req.SongData)
+	return song, nil
 }
 
-func (s *Service) GetAllSongs() (resp structsDB.GetAllSongsResp, err error) {
-	err = utils.SendRequest(nil, "get", "http://localhost:8082/api/v1/allsongs", &resp)
+func (s *Service) GetAllSongs(ctx context.Context) (resp structsDB.GetAllSongsResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAllSongs")
+	defer func() { tracing.End(span, err) }()
+
+	err = s.client.SendRequest(ctx, nil, "get", "http://localhost:8082/api/v1/allsongs", &resp)
 	if err != nil {
-		s.logger.Error("error sending request", zap.Error(err))
+		s.log(ctx).Error("error sending request", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
 	}
 
 	return resp, err
 }
 
-func (s *Service) GetSegment(id string) ([]byte, error) {
+func (s *Service) GetSongs(ctx context.Context, req structs.GetSongsReq) (resp structs.GetSongsResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetSongs")
+	defer func() { tracing.End(span, err) }()
+
+	if req.Limit <= 0 {
+		req.Limit = defaultSongsLimit
+	}
+	if req.Limit > maxSongsLimit {
+		req.Limit = maxSongsLimit
+	}
+	if req.SortBy == "" {
+		req.SortBy = "upload_time"
+	}
+	if !songsSortFields[req.SortBy] {
+		resp.Error = "unknown sort field " + req.SortBy
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Order == "" {
+		req.Order = "asc"
+	}
+	if req.Order != "asc" && req.Order != "desc" {
+		resp.Error = "order should be asc or desc"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.YearFrom != 0 && req.YearTo != 0 && req.YearFrom > req.YearTo {
+		resp.Error = "year_from is bigger than year_to"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(req.Limit))
+	query.Set("sort_by", req.SortBy)
+	query.Set("order", req.Order)
+	if req.Cursor != "" {
+		query.Set("cursor", req.Cursor)
+	}
+	if req.Band != "" {
+		query.Set("band", req.Band)
+	}
+	if req.Album != "" {
+		query.Set("album", req.Album)
+	}
+	if req.YearFrom != 0 {
+		query.Set("year_from", strconv.Itoa(req.YearFrom))
+	}
+	if req.YearTo != 0 {
+		query.Set("year_to", strconv.Itoa(req.YearTo))
+	}
+
+	err = s.client.SendRequest(ctx, nil, "get", "http://localhost:8082/api/v1/songs?"+query.Encode(), &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+// GetSong returns song from catalog, it is kept in sync with db by song changes
+// GetSong answers from search index, songs missing there are asked from db,
+// index may be not loaded yet or miss songs added by other instances
+func (s *Service) GetSong(ctx context.Context, req structs.GetSongReq) (resp structs.GetSongResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetSong")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ID == "" {
+		resp.Error = "you must fill song id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	song, ok := s.index.Get(req.ID)
+	metrics.CacheLookup(metrics.CacheCatalog, ok)
+	if ok {
+		resp.Song = song
+		return resp, nil
+	}
+
+	err = s.client.SendRequest(ctx, req, "post", "http://localhost:8082/api/v1/get_song", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.index.Add(resp.Song)
+	return resp, nil
+}
+
+func (s *Service) GetSegment(ctx context.Context, id string) (segment []byte, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetSegment")
+	defer func() { tracing.End(span, err) }()
+
 	// CHECK TOKEN!!!!!
 	req := structsDB.GetSegmentReq{
 		ID: id,
@@ -144,41 +421,44 @@ func (s *Service) GetSegment(id string) ([]byte, error) {
 
 	marshalled, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("cant marshall req", zap.Error(err))
+		s.log(ctx).Error("cant marshall req", zap.Error(err))
 		return nil, err
 	}
 
-	rawResult, err := http.Post("http://localhost:8082/api/v1/getsegment", "application/json", bytes.NewBuffer(marshalled))
+	rawResult, err := s.client.Post(ctx, "http://localhost:8082/api/v1/getsegment", "application/json", bytes.NewBuffer(marshalled))
 	if err != nil {
-		s.logger.Error("error making response to db", zap.Error(err), zap.Any("req", req))
+		s.log(ctx).Error("error making response to db", zap.Error(err), zap.Any("req", req))
 		return nil, err
 	}
 	defer rawResult.Body.Close()
 
 	data, err := ioutil.ReadAll(rawResult.Body)
 	if err != nil {
-		s.logger.Error("error reading result body", zap.Error(err), zap.Any("req", req))
+		s.log(ctx).Error("error reading result body", zap.Error(err), zap.Any("req", req))
 		return nil, err
 	}
 
 	var resp structsDB.GetSegmentResp
 	if err := json.Unmarshal(data, &resp); err != nil {
-		s.logger.Error("error unmarshalling resp from db", zap.Error(err))
+		s.log(ctx).Error("error unmarshalling resp from db", zap.Error(err))
 		return nil, err
 	}
 
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return nil, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return nil, downstreamError(resp.Error)
 	}
 
 	return resp.Segment.Data, nil
 }
 
-func (s *Service) Register(req structs2.RegisterReq) (resp structs2.NewTokenResp, err error) {
+func (s *Service) Register(ctx context.Context, req structs2.RegisterReq) (resp structs2.NewTokenResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Register")
+	defer func() { tracing.End(span, err) }()
+
 	if req.Password == "" || req.Email == "" {
 		resp.Error = "fill all the fields"
-		return resp, errors.New("fill all the fields")
+		return resp, apperr.New(apperr.Validation, "fill all the fields")
 	}
 
 	var respFromAuth structs2.RegisterResp
@@ -188,7 +468,7 @@ func (s *Service) Register(req structs2.RegisterReq) (resp structs2.NewTokenResp
 		return
 	}
 
-	respdata, err := http.Post("http://localhost:8083/api/v1/register", "application/json", bytes.NewBuffer(marshalled))
+	respdata, err := s.client.Post(ctx, "http://localhost:8083/api/v1/register", "application/json", bytes.NewBuffer(marshalled))
 	if err != nil {
 		resp.Error = err.Error()
 		return
@@ -202,15 +482,15 @@ func (s *Service) Register(req structs2.RegisterReq) (resp structs2.NewTokenResp
 
 	err = json.Unmarshal(data, &respFromAuth)
 	if err != nil {
-		s.logger.Error("error sending request to auth", zap.Error(err))
+		s.log(ctx).Error("error sending request to auth", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, errors.New("error making request")
 	}
 
 	if respFromAuth.Error != "" {
-		s.logger.Error("got error from auth", zap.Any("error", respFromAuth.Error))
+		s.log(ctx).Error("got error from auth", zap.Any("error", respFromAuth.Error))
 		resp.Error = respFromAuth.Error
-		return resp, errors.New(respFromAuth.Error)
+		return resp, downstreamError(respFromAuth.Error)
 	}
 
 	user := globalStructs.User{
@@ -230,7 +510,7 @@ func (s *Service) Register(req structs2.RegisterReq) (resp structs2.NewTokenResp
 		return
 	}
 
-	respDB, err := http.Post("http://localhost:8082/api/v1/new_user", "application/json", bytes.NewBuffer(marshalled))
+	respDB, err := s.client.Post(ctx, "http://localhost:8082/api/v1/new_user", "application/json", bytes.NewBuffer(marshalled))
 	if err != nil {
 		resp.Error = err.Error()
 		return
@@ -249,7 +529,7 @@ func (s *Service) Register(req structs2.RegisterReq) (resp structs2.NewTokenResp
 	}
 
 	if !respFromDB.OK {
-		s.logger.Error("got error from db", zap.Any("error", respFromDB.Error), zap.Any("user", user))
+		s.log(ctx).Error("got error from db", zap.Any("error", respFromDB.Error), zap.Any("user", user))
 		resp.Error = respFromDB.Error
 		return resp, err
 	}
@@ -258,296 +538,480 @@ func (s *Service) Register(req structs2.RegisterReq) (resp structs2.NewTokenResp
 	return resp, nil
 }
 
-func (s *Service) Login(req structs2.LoginReq) (resp structs2.LoginResp, err error) {
+func (s *Service) Login(ctx context.Context, req structs2.LoginReq) (resp structs2.LoginResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Login")
+	defer func() { tracing.End(span, err) }()
+
 	if req.Email == "" || req.Password == "" {
 		resp.Error = "fill all the fields"
-		return resp, errors.New(resp.Error)
+		return resp, apperr.New(apperr.Validation, resp.Error)
 	}
 
 	marshalled, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("error marshalling data", zap.Error(err))
+		s.log(ctx).Error("error marshalling data", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
-	respdata, err := http.Post("http://localhost:8083/api/v1/login", "application/json", bytes.NewBuffer(marshalled))
+	respdata, err := s.client.Post(ctx, "http://localhost:8083/api/v1/login", "application/json", bytes.NewBuffer(marshalled))
 	if err != nil {
-		s.logger.Error("error making post request to auth", zap.Error(err))
+		s.log(ctx).Error("error making post request to auth", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	data, err := ioutil.ReadAll(respdata.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
+		s.log(ctx).Error("error reading body", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	err = json.Unmarshal(data, &resp)
 	if err != nil {
-		s.logger.Error("error unmarshalling data", zap.Error(err))
+		s.log(ctx).Error("error unmarshalling data", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
+	// auth answers with error when email or password is wrong
 	if resp.Error != "" {
-		s.logger.Error("got error from auth", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from auth", zap.Any("error", resp.Error))
+		return resp, apperr.New(apperr.Unauthorized, resp.Error)
 	}
 
 	return resp, nil
 }
 
-func (s *Service) GetUserPlaylists(req structsDB.GetUserAllPlaylistsReq) (resp structsDB.GetUserAllPlaylistsResp, err error) {
+func (s *Service) GetUserPlaylists(ctx context.Context, req structsDB.GetUserAllPlaylistsReq) (resp structsDB.GetUserAllPlaylistsResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetUserPlaylists")
+	defer func() { tracing.End(span, err) }()
+
 	if req.UserID == "" {
 		resp.Error = "you must fill all ids"
-		return resp, errors.New(resp.Error)
+		return resp, apperr.New(apperr.Validation, resp.Error)
 	}
 
 	data, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("error marshalling requst", zap.Error(err))
+		s.log(ctx).Error("error marshalling requst", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
-	response, err := http.Post("http://localhost:8082/api/v1/user_playlists", "application/json", bytes.NewBuffer(data))
+	response, err := s.client.Post(ctx, "http://localhost:8082/api/v1/user_playlists", "application/json", bytes.NewBuffer(data))
 	if err != nil {
-		s.logger.Error("error making post req to db", zap.Error(err))
+		s.log(ctx).Error("error making post req to db", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
 	data, err = ioutil.ReadAll(response.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
+		s.log(ctx).Error("error reading body", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	err = json.Unmarshal(data, &resp)
 	if err != nil {
-		s.logger.Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
+		s.log(ctx).Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
 		resp.Error = err.Error()
 		return
 	}
 
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
 	}
 
 	return
 }
 
-func (s *Service) GetPlaylist(req structsDB.GetPlaylistReq) (resp structsDB.GetPlaylistResp, err error) {
+func (s *Service) GetPlaylist(ctx context.Context, req structsDB.GetPlaylistReq) (resp structsDB.GetPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetPlaylist")
+	defer func() { tracing.End(span, err) }()
+
 	if req.PlaylistID == "" {
 		resp.Error = "you must fill all ids"
-		return resp, errors.New(resp.Error)
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, req.UserID, false)
+	if err != nil {
+		resp.Error = err.Error()
+		return
 	}
+	req.UserID = ownerID
 
 	data, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("error marshalling requst", zap.Error(err))
+		s.log(ctx).Error("error marshalling requst", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
-	response, err := http.Post("http://localhost:8082/api/v1/get_playlist ", "application/json", bytes.NewBuffer(data))
+	response, err := s.client.Post(ctx, "http://localhost:8082/api/v1/get_playlist", "application/json", bytes.NewBuffer(data))
 	if err != nil {
-		s.logger.Error("error making post req to db", zap.Error(err))
+		s.log(ctx).Error("error making post req to db", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
 	data, err = ioutil.ReadAll(response.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
+		s.log(ctx).Error("error reading body", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	err = json.Unmarshal(data, &resp)
 	if err != nil {
-		s.logger.Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
+		s.log(ctx).Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
 		resp.Error = err.Error()
 		return
 	}
 
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
 	}
 
 	return
 }
 
-func (s *Service) NewPlaylist(req structsDB.NewPlaylistReq) (resp structsDB.NewPlaylistResp, err error) {
+func (s *Service) NewPlaylist(ctx context.Context, req structsDB.NewPlaylistReq) (resp structsDB.NewPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.NewPlaylist")
+	defer func() { tracing.End(span, err) }()
+
 	if req.PlaylistName == "" || req.UserID == "" {
 		resp.Error = "you must fill all ids"
-		return resp, errors.New(resp.Error)
+		return resp, apperr.New(apperr.Validation, resp.Error)
 	}
 
 	data, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("error marshalling requst", zap.Error(err))
+		s.log(ctx).Error("error marshalling requst", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
-	response, err := http.Post("http://localhost:8082/api/v1/new_playlist", "application/json", bytes.NewBuffer(data))
+	response, err := s.client.Post(ctx, "http://localhost:8082/api/v1/new_playlist", "application/json", bytes.NewBuffer(data))
 	if err != nil {
-		s.logger.Error("error making post req to db", zap.Error(err))
+		s.log(ctx).Error("error making post req to db", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
 	data, err = ioutil.ReadAll(response.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
+		s.log(ctx).Error("error reading body", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	err = json.Unmarshal(data, &resp)
 	if err != nil {
-		s.logger.Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
+		s.log(ctx).Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
 		resp.Error = err.Error()
 		return
 	}
 
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
 	}
 
 	return
 }
 
-func (s *Service) DeletePlaylist(req structsDB.DeleteUserPlaylistReq) (resp structsDB.DeleteUserPlaylistResp, err error) {
+func (s *Service) DeletePlaylist(ctx context.Context, req structsDB.DeleteUserPlaylistReq) (resp structsDB.DeleteUserPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.DeletePlaylist")
+	defer func() { tracing.End(span, err) }()
+
 	if req.PlaylistID == "" || req.UserID == "" {
 		resp.Error = "you must fill all ids"
-		return resp, errors.New(resp.Error)
+		return resp, apperr.New(apperr.Validation, resp.Error)
 	}
 
 	data, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("error marshalling requst", zap.Error(err))
+		s.log(ctx).Error("error marshalling requst", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
-	response, err := http.Post("http://localhost:8082/api/v1/delete_playlist", "application/json", bytes.NewBuffer(data))
+	response, err := s.client.Post(ctx, "http://localhost:8082/api/v1/delete_playlist", "application/json", bytes.NewBuffer(data))
 	if err != nil {
-		s.logger.Error("error making post req to db", zap.Error(err))
+		s.log(ctx).Error("error making post req to db", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
 	data, err = ioutil.ReadAll(response.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
+		s.log(ctx).Error("error reading body", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	err = json.Unmarshal(data, &resp)
 	if err != nil {
-		s.logger.Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
+		s.log(ctx).Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
 		resp.Error = err.Error()
 		return
 	}
 
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
 	}
 
 	return
 }
 
-func (s *Service) AddSongToPlaylist(req structsDB.AddSongToUserPlaylistReq) (resp structsDB.AddSongToUserPlaylistResp, err error) {
+func (s *Service) AddSongToPlaylist(ctx context.Context, req structsDB.AddSongToUserPlaylistReq) (resp structsDB.AddSongToUserPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AddSongToPlaylist")
+	defer func() { tracing.End(span, err) }()
+
 	if req.PlaylistID == "" || req.UserID == "" || req.SongID == "" {
 		resp.Error = "you must fill all ids"
-		return resp, errors.New(resp.Error)
+		return resp, apperr.New(apperr.Validation, resp.Error)
 	}
 
+	userID := req.UserID
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, userID, true)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	req.UserID = ownerID
+
+	snapshot := s.snapshotPlaylist(ctx, req.PlaylistID, ownerID)
 	data, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("error marshalling requst", zap.Error(err))
+		s.log(ctx).Error("error marshalling requst", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
-	response, err := http.Post("http://localhost:8082/api/v1/add_song_playlist", "application/json", bytes.NewBuffer(data))
+	response, err := s.client.Post(ctx, "http://localhost:8082/api/v1/add_song_playlist", "application/json", bytes.NewBuffer(data))
 	if err != nil {
-		s.logger.Error("error making post req to db", zap.Error(err))
+		s.log(ctx).Error("error making post req to db", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
 	data, err = ioutil.ReadAll(response.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
+		s.log(ctx).Error("error reading body", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	err = json.Unmarshal(data, &resp)
 	if err != nil {
-		s.logger.Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
+		s.log(ctx).Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
 		resp.Error = err.Error()
 		return
 	}
 
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
 	}
 
+	s.recordPlaylistVersion(ctx, snapshot, userID, ActionAddSong, []string{req.SongID})
 	return
 }
 
-func (s *Service) RemoveSongFromPlaylist(req structsDB.RemoveSongFromUserPlaylistReq) (resp structsDB.RemoveSongFromUserPlaylistResp, err error) {
+func (s *Service) RemoveSongFromPlaylist(ctx context.Context, req structsDB.RemoveSongFromUserPlaylistReq) (resp structsDB.RemoveSongFromUserPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.RemoveSongFromPlaylist")
+	defer func() { tracing.End(span, err) }()
+
 	if req.PlaylistID == "" || req.UserID == "" || req.SongID == "" {
 		resp.Error = "you must fill all ids"
-		return resp, errors.New(resp.Error)
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	userID := req.UserID
+	ownerID, err := s.authorizePlaylist(ctx, req.PlaylistID, userID, true)
+	if err != nil {
+		resp.Error = err.Error()
+		return
 	}
+	req.UserID = ownerID
 
+	snapshot := s.snapshotPlaylist(ctx, req.PlaylistID, ownerID)
 	data, err := json.Marshal(req)
 	if err != nil {
-		s.logger.Error("error marshalling requst", zap.Error(err))
+		s.log(ctx).Error("error marshalling requst", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
-	response, err := http.Post("http://localhost:8082/api/v1/remove_song_playlist", "application/json", bytes.NewBuffer(data))
+	response, err := s.client.Post(ctx, "http://localhost:8082/api/v1/remove_song_playlist", "application/json", bytes.NewBuffer(data))
 	if err != nil {
-		s.logger.Error("error making post req to db", zap.Error(err))
+		s.log(ctx).Error("error making post req to db", zap.Error(err))
 		resp.Error = err.Error()
 		return resp, err
 	}
 
 	data, err = ioutil.ReadAll(response.Body)
 	if err != nil {
-		s.logger.Error("error reading body", zap.Error(err))
+		s.log(ctx).Error("error reading body", zap.Error(err))
 		resp.Error = err.Error()
 		return
 	}
 
 	err = json.Unmarshal(data, &resp)
 	if err != nil {
-		s.logger.Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
+		s.log(ctx).Error("error unmarshalling resp", zap.Error(err), zap.Any("data", string(data)))
 		resp.Error = err.Error()
 		return
 	}
 
 	if resp.Error != "" {
-		s.logger.Error("got error from db", zap.Any("error", resp.Error))
-		return resp, errors.New(resp.Error)
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
 	}
 
+	s.recordPlaylistVersion(ctx, snapshot, userID, ActionRemoveSong, []string{req.SongID})
 	return
 }
+
+func (s *Service) UpdateSong(ctx context.Context, req structs.UpdateSongReq) (resp structs.UpdateSongResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateSong")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ID == "" {
+		resp.Error = "you must fill song id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	// path is generated on upload and cant be changed by user
+	req.Path = ""
+
+	err = s.client.SendRequest(ctx, req.Song, "post", "http://localhost:8082/api/v1/update_song", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.index.Add(resp.Song)
+	return
+}
+
+// PatchSong applies sent fields to current song and saves whole metadata,
+// unlike UpdateSong it can clear fields
+func (s *Service) PatchSong(ctx context.Context, req structs.PatchSongReq) (resp structs.UpdateSongResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.PatchSong")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ID == "" {
+		resp.Error = "you must fill song id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		resp.Error = "name should not be empty"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	current, err := s.GetSong(ctx, structs.GetSongReq{ID: req.ID})
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	song := current.Song
+	if req.Name != nil {
+		song.Name = *req.Name
+	}
+	if req.Band != nil {
+		song.Band = *req.Band
+	}
+	if req.Album != nil {
+		song.Album = *req.Album
+	}
+	if req.ReleaseDate != nil {
+		song.ReleaseDate = *req.ReleaseDate
+	}
+
+	// set_song replaces metadata, empty fields are saved as empty
+	err = s.client.SendRequest(ctx, song, "post", "http://localhost:8082/api/v1/set_song", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", req))
+		resp.Error = err.Error()
+		return
+	}
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+
+	s.index.Add(resp.Song)
+	return
+}
+
+func (s *Service) DeleteSong(ctx context.Context, req structs.DeleteSongReq) (resp structs.DeleteSongResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.DeleteSong")
+	defer func() { tracing.End(span, err) }()
+
+	if req.ID == "" {
+		resp.Error = "you must fill song id"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	m3u8ID := req.ID + ".m3u8"
+	m3u8, err := s.GetSegment(ctx, m3u8ID)
+	if err != nil {
+		s.log(ctx).Error("error getting song m3u8", zap.Error(err), zap.Any("id", req.ID))
+		resp.Error = err.Error()
+		return
+	}
+
+	reqToDB := structs.DeleteSongDBReq{
+		ID:         req.ID,
+		SegmentIDs: append([]string{m3u8ID}, utils.SegmentIDsFromM3U8(m3u8)...),
+	}
+	err = s.client.SendRequest(ctx, reqToDB, "post", "http://localhost:8082/api/v1/delete_song", &resp)
+	if err != nil {
+		s.log(ctx).Error("error sending request", zap.Error(err), zap.Any("req", reqToDB))
+		resp.Error = err.Error()
+		return
+	}
+
+	if resp.Error != "" {
+		s.log(ctx).Error("got error from db", zap.Any("error", resp.Error))
+		return resp, downstreamError(resp.Error)
+	}
+	s.index.Remove(req.ID)
+
+	s.removeDeletedSongFromPlaylists(ctx, req.ID)
+	return
+}
+
+// removeDeletedSongFromPlaylists removes song deleted from catalog from every playlist and records
+// playlists before removal to history. Song is already deleted, so failure is only logged and
+// playlists keep id of missing song, which readers skip like any song deleted since playlist was read.
+func (s *Service) removeDeletedSongFromPlaylists(ctx context.Context, songID string) {
+	var resp structs.RemoveSongFromAllPlaylistsResp
+	err := s.client.SendRequest(ctx, structs.RemoveSongFromAllPlaylistsReq{SongID: songID}, "post", "http://localhost:8082/api/v1/remove_song_all_playlists", &resp)
+	if err == nil && resp.Error != "" {
+		err = downstreamError(resp.Error)
+	}
+	if err != nil {
+		s.log(ctx).Error("error removing deleted song from playlists", zap.Error(err), zap.String("id", songID))
+		return
+	}
+
+	for _, playlist := range resp.Playlists {
+		snapshot := &structs.PlaylistVersion{PlaylistID: playlist.ID, Name: playlist.Name, SongIDs: playlist.SongIDs}
+		s.recordPlaylistVersion(ctx, snapshot, "", ActionDeleteSong, []string{songID})
+	}
+}
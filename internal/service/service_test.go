@@ -0,0 +1,389 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/client"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+	"go.uber.org/zap"
+)
+
+// errDown is answer of route whose downstream is not reachable
+var errDown = errors.New("connection refused")
+
+// route answers one db or auth endpoint, it returns value sent as json or errDown
+type route func(body []byte) interface{}
+
+// fakeDownstream answers calls service makes to db and auth by url path
+type fakeDownstream struct {
+	t      *testing.T
+	mu     sync.Mutex
+	routes map[string]route
+	calls  []string
+	// queries is query of the last call by path
+	queries map[string]url.Values
+}
+
+func (f *fakeDownstream) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, r.URL.Path)
+	f.queries[r.URL.Path] = r.URL.Query()
+	answer, ok := f.routes[r.URL.Path]
+	f.mu.Unlock()
+	if !ok {
+		f.t.Errorf("unexpected call to %s", r.URL.Path)
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "not found"}), nil
+	}
+
+	result := answer(body)
+	if err, ok := result.(error); ok {
+		return nil, err
+	}
+	return jsonResponse(http.StatusOK, result), nil
+}
+
+// handle sets route of path, tests use it to change answers between calls
+func (f *fakeDownstream) handle(path string, answer route) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[path] = answer
+}
+
+// called returns paths called so far in order
+func (f *fakeDownstream) called() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeDownstream) query(path string) url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries[path]
+}
+
+func (f *fakeDownstream) count(path string) int {
+	n := 0
+	for _, called := range f.called() {
+		if called == path {
+			n++
+		}
+	}
+	return n
+}
+
+func jsonResponse(status int, v interface{}) *http.Response {
+	data, _ := json.Marshal(v)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(data)),
+	}
+}
+
+// answer returns route always answering v
+func answer(v interface{}) route {
+	return func([]byte) interface{} { return v }
+}
+
+// decode unmarshals request body sent to route
+func decode(t *testing.T, body []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("bad request body %s: %v", body, err)
+	}
+}
+
+// newTestService creates service without background jobs talking to fake downstream
+func newTestService(t *testing.T, routes map[string]route) (*Service, *fakeDownstream) {
+	if routes == nil {
+		routes = make(map[string]route)
+	}
+	f := &fakeDownstream{t: t, routes: routes, queries: make(map[string]url.Values)}
+	s := newService(zap.NewNop(), client.NewWithTransport(zap.NewNop(), downstreamPolicies, f))
+	s.probe = &http.Client{Transport: f}
+	t.Cleanup(func() { s.Close(context.Background()) })
+	return s, f
+}
+
+func TestGetSongs(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       structs.GetSongsReq
+		wantQuery string
+		wantCode  apperr.Code
+	}{
+		{name: "defaults", wantQuery: "limit=50&order=asc&sort_by=upload_time"},
+		{
+			name:      "filters and cursor",
+			req:       structs.GetSongsReq{Cursor: "c1", Limit: 10, SortBy: "release_date", Order: "desc", Band: "Abba", Album: "Waterloo", YearFrom: 1970, YearTo: 1979},
+			wantQuery: "album=Waterloo&band=Abba&cursor=c1&limit=10&order=desc&sort_by=release_date&year_from=1970&year_to=1979",
+		},
+		{name: "limit is capped", req: structs.GetSongsReq{Limit: 10000}, wantQuery: "limit=500&order=asc&sort_by=upload_time"},
+		{name: "unknown sort field", req: structs.GetSongsReq{SortBy: "path"}, wantCode: apperr.Validation},
+		{name: "unknown order", req: structs.GetSongsReq{Order: "up"}, wantCode: apperr.Validation},
+		{name: "years swapped", req: structs.GetSongsReq{YearFrom: 1980, YearTo: 1970}, wantCode: apperr.Validation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/songs": answer(structs.GetSongsResp{NextCursor: "c2"}),
+			})
+
+			resp, err := s.GetSongs(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				if f.count("/api/v1/songs") != 0 {
+					t.Error("invalid page is asked from db")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetSongs() error = %v", err)
+			}
+			if got := f.query("/api/v1/songs").Encode(); got != tt.wantQuery {
+				t.Errorf("query = %s, want %s", got, tt.wantQuery)
+			}
+			if resp.NextCursor != "c2" {
+				t.Errorf("next cursor = %q", resp.NextCursor)
+			}
+		})
+	}
+}
+
+func TestUpdateSong(t *testing.T) {
+	var sent globalStructs.Song
+	s, _ := newTestService(t, map[string]route{
+		"/api/v1/update_song": func(body []byte) interface{} {
+			decode(t, body, &sent)
+			song := sent
+			song.Path = "/segments/1.m3u8"
+			return structs.UpdateSongResp{Song: song}
+		},
+	})
+
+	_, err := s.UpdateSong(context.Background(), structs.UpdateSongReq{Song: globalStructs.Song{ID: "1", Name: "Help", Path: "/etc/passwd"}})
+	if err != nil {
+		t.Fatalf("UpdateSong() error = %v", err)
+	}
+	if sent.Path != "" {
+		t.Errorf("path %q is sent to db", sent.Path)
+	}
+	if song, ok := s.index.Get("1"); !ok || song.Name != "Help" {
+		t.Errorf("index has %+v, %v, want updated song", song, ok)
+	}
+
+	if _, err := s.UpdateSong(context.Background(), structs.UpdateSongReq{}); apperr.CodeOf(err) != apperr.Validation {
+		t.Errorf("no id err = %v, want validation", err)
+	}
+}
+
+func TestPatchSong(t *testing.T) {
+	current := globalStructs.Song{ID: "1", Name: "Help", Band: "The Beatles", Album: "Help!", Path: "/segments/1.m3u8"}
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		req      structs.PatchSongReq
+		want     globalStructs.Song
+		wantCode apperr.Code
+	}{
+		{
+			name: "rename",
+			req:  structs.PatchSongReq{ID: "1", Name: str("Help!")},
+			want: globalStructs.Song{ID: "1", Name: "Help!", Band: "The Beatles", Album: "Help!", Path: "/segments/1.m3u8"},
+		},
+		{
+			name: "clear album",
+			req:  structs.PatchSongReq{ID: "1", Album: str("")},
+			want: globalStructs.Song{ID: "1", Name: "Help", Band: "The Beatles", Path: "/segments/1.m3u8"},
+		},
+		{name: "empty name", req: structs.PatchSongReq{ID: "1", Name: str(" ")}, wantCode: apperr.Validation},
+		{name: "no id", req: structs.PatchSongReq{Band: str("Beatles")}, wantCode: apperr.Validation},
+		{name: "unknown song", req: structs.PatchSongReq{ID: "2", Band: str("Beatles")}, wantCode: apperr.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent globalStructs.Song
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/get_song": answer(structs.GetSongResp{Error: "mongo: no documents in result"}),
+				"/api/v1/set_song": func(body []byte) interface{} {
+					decode(t, body, &sent)
+					return structs.UpdateSongResp{Song: sent}
+				},
+			})
+			s.index.Add(current)
+
+			_, err := s.PatchSong(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				if f.count("/api/v1/set_song") != 0 {
+					t.Error("song is saved on failed patch")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PatchSong() error = %v", err)
+			}
+			if sent != tt.want {
+				t.Errorf("saved %+v, want %+v", sent, tt.want)
+			}
+			if song, _ := s.index.Get("1"); song != tt.want {
+				t.Errorf("index has %+v, want %+v", song, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteSong(t *testing.T) {
+	tests := []struct {
+		name       string
+		deleteSong route
+		removeSong route
+		wantCode   apperr.Code
+		wantCalls  []string
+		// wantVersions are playlists recorded to history
+		wantVersions []string
+	}{
+		{
+			name:         "deleted",
+			deleteSong:   answer(structs.DeleteSongResp{OK: true}),
+			removeSong:   answer(structs.RemoveSongFromAllPlaylistsResp{OK: true, Playlists: []globalStructs.Playlist{{ID: "p1", SongIDs: []string{"2", "1"}}, {ID: "p2", SongIDs: []string{"1"}}}}),
+			wantCalls:    []string{"/api/v1/getsegment", "/api/v1/delete_song", "/api/v1/remove_song_all_playlists", "/api/v1/add_playlist_version", "/api/v1/add_playlist_version"},
+			wantVersions: []string{"p1", "p2"},
+		},
+		{
+			// playlists keep id of deleted song, song is gone anyway
+			name:       "playlists cleanup failed",
+			deleteSong: answer(structs.DeleteSongResp{OK: true}),
+			removeSong: answer(structs.RemoveSongFromAllPlaylistsResp{Error: "write conflict"}),
+			wantCalls:  []string{"/api/v1/getsegment", "/api/v1/delete_song", "/api/v1/remove_song_all_playlists"},
+		},
+		{
+			name:       "delete failed",
+			deleteSong: answer(errDown),
+			wantCode:   apperr.UpstreamUnavailable,
+			wantCalls:  []string{"/api/v1/getsegment", "/api/v1/delete_song"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted structs.DeleteSongDBReq
+			var versions []string
+			s, f := newTestService(t, map[string]route{
+				"/api/v1/getsegment": answer(structsDB.GetSegmentResp{Segment: globalStructs.SongData{
+					Data: []byte("#EXTM3U\n#EXTINF:10,\n/segments/1_000.ts\n#EXTINF:10,\n/segments/1_001.ts\n"),
+				}}),
+				"/api/v1/delete_song": func(body []byte) interface{} {
+					decode(t, body, &deleted)
+					return tt.deleteSong(body)
+				},
+				"/api/v1/remove_song_all_playlists": tt.removeSong,
+				"/api/v1/add_playlist_version": func(body []byte) interface{} {
+					var version structs.PlaylistVersion
+					decode(t, body, &version)
+					if version.Action != ActionDeleteSong || !reflect.DeepEqual(version.ChangedSongIDs, []string{"1"}) || !contains(version.SongIDs, "1") {
+						t.Errorf("recorded version %+v, want playlist before song 1 was deleted", version)
+					}
+					versions = append(versions, version.PlaylistID)
+					return structs.AddPlaylistVersionResp{Version: 1}
+				},
+			})
+			s.index.Add(globalStructs.Song{ID: "1", Name: "Help"})
+
+			_, err := s.DeleteSong(context.Background(), structs.DeleteSongReq{ID: "1"})
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+			} else if err != nil {
+				t.Fatalf("DeleteSong() error = %v", err)
+			}
+
+			if calls := f.called(); !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if want := []string{"1.m3u8", "1_000.ts", "1_001.ts"}; !reflect.DeepEqual(deleted.SegmentIDs, want) {
+				t.Errorf("deleted segments %v, want %v", deleted.SegmentIDs, want)
+			}
+			if !reflect.DeepEqual(versions, tt.wantVersions) {
+				t.Errorf("versions of %v, want %v", versions, tt.wantVersions)
+			}
+			if _, ok := s.index.Get("1"); ok != (tt.wantCode != "") {
+				t.Errorf("song in index = %v after delete", ok)
+			}
+		})
+	}
+}
+
+func contains(ids []string, id string) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClose(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     func(s *Service, release chan struct{})
+		wantErr error
+	}{
+		{name: "no jobs", job: func(*Service, chan struct{}) {}},
+		{
+			name: "job stops on cancel",
+			job: func(s *Service, release chan struct{}) {
+				s.goJob(func() { <-s.ctx.Done() })
+			},
+		},
+		{
+			// close gives up waiting, job is left to finish on its own
+			name: "job ignores cancel",
+			job: func(s *Service, release chan struct{}) {
+				s.goJob(func() { <-release })
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, nil)
+			release := make(chan struct{})
+			defer close(release)
+			tt.job(s, release)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			if err := s.Close(ctx); err != tt.wantErr {
+				t.Fatalf("Close() = %v, want %v", err, tt.wantErr)
+			}
+			if s.goJob(func() { t.Error("job started after close") }) {
+				t.Error("goJob() accepted job after close")
+			}
+		})
+	}
+}
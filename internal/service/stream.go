@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/hls"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	"go.uber.org/zap"
+)
+
+// streamWorkers limits parallel m3u8 requests to db while building playlist stream
+const streamWorkers = 8
+
+// segmentURIPrefix is path segments are served from by GetSegment handler
+const segmentURIPrefix = "/segments/"
+
+// GetPlaylistStream builds one HLS media playlist playing all songs of user playlist.
+// Songs whose m3u8 cant be loaded are skipped.
+func (s *Service) GetPlaylistStream(ctx context.Context, req structsDB.GetPlaylistReq) (stream []byte, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetPlaylistStream")
+	defer func() { tracing.End(span, err) }()
+
+	playlist, err := s.GetPlaylist(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	songIDs := playlist.Playlist.SongIDs
+	tracks := make([]*hls.MediaPlaylist, len(songIDs))
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for w := 0; w < streamWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				tracks[i] = s.getSongMediaPlaylist(ctx, songIDs[i])
+			}
+		}()
+	}
+	for i := range songIDs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var result []hls.MediaPlaylist
+	for _, track := range tracks {
+		if track != nil {
+			result = append(result, *track)
+		}
+	}
+	if len(result) == 0 {
+		return nil, apperr.New(apperr.NotFound, "playlist has no playable songs")
+	}
+
+	// user id in segment urls lets play tracker know who listens
+	var uriSuffix string
+	if req.UserID != "" {
+		uriSuffix = "?user_id=" + url.QueryEscape(req.UserID)
+	}
+	return hls.Join(result, segmentURIPrefix, uriSuffix), nil
+}
+
+func (s *Service) getSongMediaPlaylist(ctx context.Context, songID string) *hls.MediaPlaylist {
+	data, err := s.GetSegment(ctx, songID+".m3u8")
+	if err != nil {
+		s.log(ctx).Error("error getting song m3u8", zap.Error(err), zap.String("song_id", songID))
+		return nil
+	}
+
+	track, err := hls.Parse(data)
+	if err != nil {
+		s.log(ctx).Error("error parsing song m3u8", zap.Error(err), zap.String("song_id", songID))
+		return nil
+	}
+	return &track
+}
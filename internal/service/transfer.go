@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/playlistfmt"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+	"go.uber.org/zap"
+)
+
+const defaultImportedPlaylistName = "Imported playlist"
+
+// ExportPlaylist writes user playlist in requested format, m3u by default.
+// Songs are resolved like GetSong, so cold index falls back to db. Songs deleted
+// from catalog are skipped, other lookup errors fail the export instead of truncating it.
+func (s *Service) ExportPlaylist(ctx context.Context, req structs.ExportPlaylistReq) (data []byte, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ExportPlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.Format == "" {
+		req.Format = playlistfmt.FormatM3U
+	}
+	switch req.Format {
+	case playlistfmt.FormatM3U, playlistfmt.FormatM3U8, playlistfmt.FormatXSPF, playlistfmt.FormatJSON:
+	default:
+		return nil, apperr.New(apperr.Validation, "format should be m3u, m3u8, xspf or json")
+	}
+
+	playlist, err := s.GetPlaylist(ctx, structsDB.GetPlaylistReq{PlaylistID: req.PlaylistID, UserID: req.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := playlistfmt.Playlist{Name: playlist.Playlist.Name}
+	for _, id := range playlist.Playlist.SongIDs {
+		found, err := s.GetSong(ctx, structs.GetSongReq{ID: id})
+		if apperr.CodeOf(err) == apperr.NotFound {
+			s.log(ctx).Warn("exported song not found in catalog", zap.String("song_id", id))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		song := found.Song
+		doc.Entries = append(doc.Entries, playlistfmt.Entry{
+			Title:    song.Name,
+			Artist:   song.Band,
+			Album:    song.Album,
+			Location: song.Path,
+		})
+	}
+
+	return playlistfmt.Encode(req.Format, doc)
+}
+
+// ImportPlaylist creates new playlist with every entry of the file found in catalog,
+// entries not found are returned in Unmatched
+func (s *Service) ImportPlaylist(ctx context.Context, req structs.ImportPlaylistReq) (resp structs.ImportPlaylistResp, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ImportPlaylist")
+	defer func() { tracing.End(span, err) }()
+
+	if req.UserID == "" || len(req.Data) == 0 {
+		resp.Error = "fill all the fields"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	doc, err := playlistfmt.Decode(req.Format, req.Data)
+	if err != nil {
+		s.log(ctx).Error("error decoding playlist", zap.Error(err), zap.String("format", req.Format))
+		resp.Error = err.Error()
+		return resp, apperr.Wrap(apperr.Validation, err)
+	}
+	if len(doc.Entries) == 0 {
+		resp.Error = "playlist is empty"
+		return resp, apperr.New(apperr.Validation, resp.Error)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = doc.Name
+	}
+	if name == "" {
+		name = defaultImportedPlaylistName
+	}
+
+	// entries are matched against whole catalog, empty index would match nothing
+	if !s.index.Loaded() && s.loadSearchIndex() != nil {
+		resp.Error = "songs catalog is not available"
+		return resp, apperr.New(apperr.UpstreamUnavailable, resp.Error)
+	}
+	matcher := newSongMatcher(s.index.Songs(), s.artists)
+	var matched []globalStructs.Song
+	var matchedEntries []playlistfmt.Entry
+	for _, entry := range doc.Entries {
+		song, ok := matcher.match(entry)
+		if !ok {
+			resp.Unmatched = append(resp.Unmatched, structs.PlaylistEntry(entry))
+			continue
+		}
+		matched = append(matched, song)
+		matchedEntries = append(matchedEntries, entry)
+	}
+
+	newPlaylist, err := s.NewPlaylist(ctx, structsDB.NewPlaylistReq{UserID: req.UserID, PlaylistName: name})
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	resp.PlaylistID = newPlaylist.Playlist.ID
+
+	// playlist was just created by user, so songs are added in batches without access
+	// checks and whole import is recorded as one version of empty playlist
+	var added []string
+	for start := 0; start < len(matched); start += maxPlaylistBatch {
+		end := start + maxPlaylistBatch
+		if end > len(matched) {
+			end = len(matched)
+		}
+		batch := structs.AddSongsToPlaylistReq{UserID: req.UserID, PlaylistID: resp.PlaylistID}
+		for _, song := range matched[start:end] {
+			batch.SongIDs = append(batch.SongIDs, song.ID)
+		}
+
+		var respAdd structs.AddSongsToPlaylistResp
+		err := s.client.SendRequest(ctx, batch, "post", "http://localhost:8082/api/v1/add_songs_playlist", &respAdd)
+		if err == nil && respAdd.Error != "" {
+			err = downstreamError(respAdd.Error)
+		}
+		if err != nil {
+			s.log(ctx).Error("error adding imported songs", zap.Error(err), zap.String("playlist_id", resp.PlaylistID))
+			for _, entry := range matchedEntries[start:end] {
+				resp.Unmatched = append(resp.Unmatched, structs.PlaylistEntry(entry))
+			}
+			continue
+		}
+		added = append(added, batch.SongIDs...)
+	}
+	resp.Matched = len(added)
+
+	if len(added) > 0 {
+		snapshot := &structs.PlaylistVersion{PlaylistID: resp.PlaylistID, Name: name}
+		s.recordPlaylistVersion(ctx, snapshot, req.UserID, ActionAddSongs, added)
+	}
+	return resp, nil
+}
+
+// songMatcher finds catalog songs by location or by title, artist and album.
+// Artists are compared by artist key, so alias in playlist matches band of song.
+type songMatcher struct {
+	byPath  map[string]globalStructs.Song
+	byTitle map[string][]globalStructs.Song
+	artists *artistNames
+}
+
+func newSongMatcher(songs []globalStructs.Song, artists *artistNames) songMatcher {
+	m := songMatcher{
+		byPath:  make(map[string]globalStructs.Song, len(songs)),
+		byTitle: make(map[string][]globalStructs.Song, len(songs)),
+		artists: artists,
+	}
+	for _, song := range songs {
+		m.byPath[song.Path] = song
+		title := normalizeName(song.Name)
+		m.byTitle[title] = append(m.byTitle[title], song)
+	}
+	return m
+}
+
+// match requires the same title, artist must match if entry has it, album is used to pick between candidates
+func (m songMatcher) match(entry playlistfmt.Entry) (globalStructs.Song, bool) {
+	if song, ok := m.byPath[entry.Location]; ok && entry.Location != "" {
+		return song, true
+	}
+
+	artist, album := "", normalizeName(entry.Album)
+	if entry.Artist != "" {
+		artist = m.artists.key(entry.Artist)
+	}
+	var best globalStructs.Song
+	bestScore := -1
+	for _, candidate := range m.byTitle[normalizeName(entry.Title)] {
+		score := 0
+		if artist != "" {
+			if m.artists.key(candidate.Band) != artist {
+				continue
+			}
+			score += 2
+		}
+		if album != "" && normalizeName(candidate.Album) == album {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best, bestScore >= 0
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/playlistfmt"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/shared/structs"
+	structsDB "github.com/supperdoggy/spotify-web-project/spotify-db/shared/structs"
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
+
+func ownerAccess(ownerID string) route {
+	return answer(structs.GetPlaylistAccessResp{Access: structs.PlaylistAccess{OwnerID: ownerID}})
+}
+
+func TestExportPlaylist(t *testing.T) {
+	songs := map[string]globalStructs.Song{
+		"1": {ID: "1", Name: "Yesterday", Band: "The Beatles", Path: "/segments/1.m3u8"},
+		"2": {ID: "2", Name: "Hells Bells", Band: "AC/DC", Path: "/segments/2.m3u8"},
+	}
+	getSong := func(body []byte) interface{} {
+		var req structs.GetSongReq
+		decode(t, body, &req)
+		song, ok := songs[req.ID]
+		if !ok {
+			return structs.GetSongResp{Error: "mongo: no documents in result"}
+		}
+		return structs.GetSongResp{Song: song}
+	}
+
+	tests := []struct {
+		name string
+		// indexed songs, the rest is asked from db
+		indexed   []globalStructs.Song
+		getSong   route
+		wantTitle []string
+		wantCode  apperr.Code
+	}{
+		{name: "songs from index", indexed: []globalStructs.Song{songs["1"], songs["2"]}, getSong: getSong, wantTitle: []string{"Yesterday", "Hells Bells"}},
+		// deleted song is skipped, others are taken from db
+		{name: "cold index", getSong: getSong, wantTitle: []string{"Yesterday", "Hells Bells"}},
+		{name: "db is down", indexed: []globalStructs.Song{songs["1"]}, getSong: answer(errDown), wantCode: apperr.UpstreamUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, map[string]route{
+				"/api/v1/playlist_access": ownerAccess("u1"),
+				"/api/v1/get_playlist": answer(structsDB.GetPlaylistResp{Playlist: globalStructs.Playlist{
+					ID: "p1", Name: "Mix", SongIDs: []string{"1", "deleted", "2"},
+				}}),
+				"/api/v1/get_song": tt.getSong,
+			})
+			for _, song := range tt.indexed {
+				s.index.Add(song)
+			}
+
+			data, err := s.ExportPlaylist(context.Background(), structs.ExportPlaylistReq{UserID: "u1", PlaylistID: "p1", Format: playlistfmt.FormatJSON})
+			if tt.wantCode != "" {
+				if apperr.CodeOf(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportPlaylist() error = %v", err)
+			}
+			doc, err := playlistfmt.Decode(playlistfmt.FormatJSON, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, entry := range doc.Entries {
+				titles = append(titles, entry.Title)
+			}
+			if len(titles) != len(tt.wantTitle) || titles[0] != tt.wantTitle[0] || titles[1] != tt.wantTitle[1] {
+				t.Errorf("exported %v, want %v", titles, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestImportPlaylistErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      structs.ImportPlaylistReq
+		allSongs route
+		wantCode apperr.Code
+	}{
+		{"no data", structs.ImportPlaylistReq{UserID: "u1"}, nil, apperr.Validation},
+		{"malformed json", structs.ImportPlaylistReq{UserID: "u1", Format: playlistfmt.FormatJSON, Data: []byte(`{"name":`)}, nil, apperr.Validation},
+		{"unknown format", structs.ImportPlaylistReq{UserID: "u1", Format: "pls", Data: []byte("[playlist]")}, nil, apperr.Validation},
+		{"empty playlist", structs.ImportPlaylistReq{UserID: "u1", Data: []byte("#EXTM3U\n")}, nil, apperr.Validation},
+		// nothing could be matched against empty catalog
+		{"catalog not loaded", structs.ImportPlaylistReq{UserID: "u1", Data: []byte("yesterday.mp3\n")}, answer(errDown), apperr.UpstreamUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := map[string]route{}
+			if tt.allSongs != nil {
+				routes["/api/v1/allsongs"] = tt.allSongs
+				routes["/api/v1/all_artists"] = answer(structs.GetAllArtistsResp{})
+			}
+			s, f := newTestService(t, routes)
+
+			_, err := s.ImportPlaylist(context.Background(), tt.req)
+			if apperr.CodeOf(err) != tt.wantCode {
+				t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+			}
+			if f.count("/api/v1/new_playlist") != 0 {
+				t.Error("playlist is created on failed import")
+			}
+		})
+	}
+}
+
+func TestImportPlaylistLoadsCatalog(t *testing.T) {
+	s, f := newTestService(t, map[string]route{
+		"/api/v1/allsongs": answer(structsDB.GetAllSongsResp{Songs: []globalStructs.Song{
+			{ID: "1", Name: "Yesterday", Band: "The Beatles"},
+		}}),
+		"/api/v1/all_artists":          answer(structs.GetAllArtistsResp{}),
+		"/api/v1/new_playlist":         answer(structsDB.NewPlaylistResp{Playlist: globalStructs.Playlist{ID: "p1"}}),
+		"/api/v1/add_songs_playlist":   answer(structs.AddSongsToPlaylistResp{OK: true}),
+		"/api/v1/add_playlist_version": answer(structs.AddPlaylistVersionResp{Version: 1}),
+	})
+
+	resp, err := s.ImportPlaylist(context.Background(), structs.ImportPlaylistReq{
+		UserID: "u1",
+		Data:   []byte("#EXTM3U\n#EXTINF:1,The Beatles - Yesterday\ny.mp3\n#EXTINF:1,Abba - Waterloo\nw.mp3\n"),
+	})
+	if err != nil {
+		t.Fatalf("ImportPlaylist() error = %v", err)
+	}
+	if resp.Matched != 1 || len(resp.Unmatched) != 1 || resp.Unmatched[0].Title != "Waterloo" {
+		t.Errorf("ImportPlaylist() = %+v, want Yesterday matched and Waterloo unmatched", resp)
+	}
+	if f.count("/api/v1/allsongs") != 1 {
+		t.Errorf("catalog is loaded %d times, want once", f.count("/api/v1/allsongs"))
+	}
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package utils
+
+import "os/exec"
+
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package utils
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestConvMp3ToM3U8Cancel(t *testing.T) {
+	// script leaves child holding output open like ffmpeg does
+	workDir(t)
+	if err := os.WriteFile(TranscodeScript, []byte("sleep 30 &\nwait\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, _, err := ConvMp3ToM3U8(ctx, zap.NewNop(), "1.mp3", "1")
+	if err == nil {
+		t.Fatal("cancelled transcode succeeded")
+	}
+	// without killing process group output is held until WaitDelay
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("transcode stopped after %s", elapsed)
+	}
+}
@@ -2,38 +2,97 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/apperr"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/logging"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
 	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var tracer = otel.Tracer("github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils")
+
+// working files of song upload
+const (
+	MP3Dir          = "example/mp3"
+	SegmentsDir     = "example/songs"
+	TranscodeScript = "example/create.sh"
 )
 
 func CreateMP3File(name string, data []byte) error {
-	outputfile, err := os.Create("example/mp3/" + name + ".mp3")
+	outputfile, err := os.Create(MP3Dir + "/" + name + ".mp3")
 	if err != nil {
 		return err
 	}
 	buf := bytes.NewBuffer(data)
 	_, err = io.Copy(outputfile, buf)
+	if closeErr := outputfile.Close(); err == nil {
+		err = closeErr
+	}
 	return err
 }
 
-func ConvMp3ToM3U8(logger *zap.Logger, filename, m3p8 string) (m3u8Data *globalStructs.SongData, tsData []globalStructs.SongData, err error) {
-	args := []string{"example/create.sh", "example/mp3/" + filename, "example/songs/" + m3p8 + ".m3u8", "example/songs/" + m3p8 + "_%03d.ts"}
-	cmd := exec.Command("/bin/sh", args...)
+// RemoveUploadFiles removes mp3 and segment files of upload name
+func RemoveUploadFiles(name string) error {
+	paths, err := filepath.Glob(fmt.Sprintf("%s/%s_*.ts", SegmentsDir, name))
+	if err != nil {
+		return err
+	}
+	paths = append(paths, fmt.Sprintf("%s/%s.mp3", MP3Dir, name), fmt.Sprintf("%s/%s.m3u8", SegmentsDir, name))
+	return removeFiles(paths)
+}
+
+// CleanUploadDirs removes files left by uploads that were interrupted
+func CleanUploadDirs() error {
+	var paths []string
+	for _, pattern := range []string{MP3Dir + "/*.mp3", SegmentsDir + "/*.m3u8", SegmentsDir + "/*.ts"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	return removeFiles(paths)
+}
+
+func removeFiles(paths []string) error {
+	var firstErr error
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ConvMp3ToM3U8 runs ffmpeg script, it is killed together with ffmpeg when ctx is done
+func ConvMp3ToM3U8(ctx context.Context, logger *zap.Logger, filename, m3p8 string) (m3u8Data *globalStructs.SongData, tsData []globalStructs.SongData, err error) {
+	args := []string{TranscodeScript, MP3Dir + "/" + filename, SegmentsDir + "/" + m3p8 + ".m3u8", SegmentsDir + "/" + m3p8 + "_%03d.ts"}
+	ctx, span := tracer.Start(ctx, "ffmpeg", trace.WithAttributes(attribute.StringSlice("args", args)))
+	cmd := exec.CommandContext(ctx, "/bin/sh", args...)
+	killProcessGroup(cmd)
 	_, err = cmd.CombinedOutput()
+	tracing.End(span, err)
 	if err != nil {
 		logger.Error("error getting output", zap.Error(err))
 		return nil, nil, err
 	}
 
-	data, err := os.ReadFile(fmt.Sprintf("example/songs/%s.m3u8", m3p8))
+	data, err := os.ReadFile(fmt.Sprintf("%s/%s.m3u8", SegmentsDir, m3p8))
 	if err != nil {
 		logger.Error("error reading m3u8 file", zap.Error(err))
 		return nil, nil, err
@@ -47,7 +106,7 @@ func ConvMp3ToM3U8(logger *zap.Logger, filename, m3p8 string) (m3u8Data *globalS
 	tsData = []globalStructs.SongData{}
 	i := 0
 	for {
-		path := fmt.Sprintf("example/songs/%s_%03d.ts", m3p8, i)
+		path := fmt.Sprintf("%s/%s_%03d.ts", SegmentsDir, m3p8, i)
 		data, err := os.ReadFile(path)
 		if err != nil {
 			break
@@ -66,7 +125,7 @@ func ConvMp3ToM3U8(logger *zap.Logger, filename, m3p8 string) (m3u8Data *globalS
 		}
 	}
 
-	err = os.Remove(fmt.Sprintf("example/songs/%s.m3u8", m3p8))
+	err = os.Remove(fmt.Sprintf("%s/%s.m3u8", SegmentsDir, m3p8))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -78,39 +137,6 @@ func ConvMp3ToM3U8(logger *zap.Logger, filename, m3p8 string) (m3u8Data *globalS
 	return
 }
 
-func SendRequest(req interface{}, method, url string, resp interface{}) error {
-	var respFromServer *http.Response
-	var data []byte
-	var err error
-
-	if req == nil {
-		data, err = json.Marshal(req)
-		if err != nil {
-			return err
-		}
-	}
-	switch method {
-	case "post":
-		respFromServer, err = http.Post(url, "application/json", bytes.NewBuffer(data))
-	case "get":
-		respFromServer, err = http.Get(url)
-	}
-	if err != nil {
-		return err
-	}
-	defer respFromServer.Body.Close()
-
-	data, err = ioutil.ReadAll(respFromServer.Body)
-	if err != nil {
-		return err
-	}
-	err = json.Unmarshal(data, &resp)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func SendJson(w http.ResponseWriter, obj interface{}, code int) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -122,11 +148,36 @@ func SendJson(w http.ResponseWriter, obj interface{}, code int) error {
 	return err
 }
 
+// ParseJson unmarshals request body to obj and remembers user id from it for access log,
+// errors are apperr.Validation as body is what client sent
 func ParseJson(r *http.Request, obj interface{}) error {
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return err
+		return apperr.Wrap(apperr.Validation, err)
 	}
 
-	return json.Unmarshal(data, obj)
+	// user id key differs between our and db request structs
+	var user struct {
+		UserID   string `json:"user_id"`
+		DBUserID string `json:"UserID"`
+	}
+	if json.Unmarshal(data, &user) == nil {
+		logging.SetUserID(r.Context(), user.UserID)
+		logging.SetUserID(r.Context(), user.DBUserID)
+	}
+
+	return apperr.Wrap(apperr.Validation, json.Unmarshal(data, obj))
+}
+
+// SegmentIDsFromM3U8 returns ids of all ts segments listed in m3u8 document
+func SegmentIDsFromM3U8(data []byte) []string {
+	var ids []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, path.Base(line))
+	}
+	return ids
 }
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// workDir runs test in temp dir holding upload dirs with files
+func workDir(t *testing.T, files ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, d := range []string{MP3Dir, SegmentsDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range files {
+		if err := os.WriteFile(f, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// left returns files left in upload dirs
+func left(t *testing.T) []string {
+	t.Helper()
+	var files []string
+	for _, d := range []string{MP3Dir, SegmentsDir} {
+		matches, err := filepath.Glob(d + "/*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestUploadFilesCleanup(t *testing.T) {
+	files := []string{
+		MP3Dir + "/1.mp3", SegmentsDir + "/1.m3u8", SegmentsDir + "/1_000.ts", SegmentsDir + "/1_001.ts",
+		MP3Dir + "/2.mp3", SegmentsDir + "/2_000.ts",
+		SegmentsDir + "/.keep",
+	}
+
+	tests := []struct {
+		name  string
+		clean func() error
+		want  []string
+	}{
+		{
+			name:  "one upload",
+			clean: func() error { return RemoveUploadFiles("1") },
+			want:  []string{MP3Dir + "/2.mp3", SegmentsDir + "/.keep", SegmentsDir + "/2_000.ts"},
+		},
+		{
+			// files of other kinds are not upload leftovers
+			name:  "all uploads",
+			clean: CleanUploadDirs,
+			want:  []string{SegmentsDir + "/.keep"},
+		},
+		// nil want is every file kept
+		{name: "upload without files", clean: func() error { return RemoveUploadFiles("3") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir(t, files...)
+			want := tt.want
+			if want == nil {
+				want = left(t)
+			}
+			if err := tt.clean(); err != nil {
+				t.Fatal(err)
+			}
+			if got := left(t); !reflect.DeepEqual(got, want) {
+				t.Errorf("left %v, want %v", got, want)
+			}
+		})
+	}
+}
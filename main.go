@@ -1,25 +1,110 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/cors"
 	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/handlers"
 	service2 "github.com/supperdoggy/spotify-web-project/spotify-back/internal/service"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/tracing"
+	"github.com/supperdoggy/spotify-web-project/spotify-back/internal/utils"
 	"go.uber.org/zap"
-	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout is how long in-flight requests are waited for on shutdown
+const shutdownTimeout = 30 * time.Second
+
+// closeTimeout is how long background jobs and traces are waited for after requests
+const closeTimeout = 10 * time.Second
+
 func main() {
 	logger, _ := zap.NewDevelopment()
 	// configure the songs directory name and port
 	const port = 8080
+
+	// traces exporter is stdout, otlp or none
+	shutdownTracing, err := tracing.Init(context.Background(), os.Getenv("OTEL_TRACES_EXPORTER"))
+	if err != nil {
+		logger.Fatal("error initializing tracing", zap.Error(err))
+	}
+
+	corsConfig, err := cors.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("error reading cors config", zap.Error(err))
+	}
+
+	// files left by uploads of previous run, if it could not drain them on shutdown
+	if err := utils.CleanUploadDirs(); err != nil {
+		logger.Error("error cleaning upload files", zap.Error(err))
+	}
+
 	// test
 	service := service2.NewService(logger)
 	h := handlers.NewHandlers(logger, service)
 	h.InitHandlers()
 
+	// requests are cancelled when shutdownTimeout passes, it kills their ffmpeg and db calls
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:        fmt.Sprintf(":%v", port),
+		Handler:     cors.Handler(corsConfig, h.Handler()),
+		BaseContext: func(net.Listener) context.Context { return requestsCtx },
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
 	fmt.Printf("Starting server on %v\n", port)
 
 	// serve and log errors
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%v", port), nil))
+	serving := make(chan error, 1)
+	go func() {
+		serving <- server.ListenAndServe()
+	}()
+	failed := false
+	select {
+	case err := <-serving:
+		logger.Error("error serving", zap.Error(err))
+		failed = true
+	case <-signals.Done():
+		// second signal kills process right away
+		stopSignals()
+		logger.Info("shutting down, draining requests", zap.Duration("timeout", shutdownTimeout))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	drained := true
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("error draining requests", zap.Error(err))
+		drained = false
+	}
+	cancelRequests()
+
+	// requests had their own timeout, background jobs get fresh one
+	closeCtx, cancelClose := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancelClose()
+	if err := service.Close(closeCtx); err != nil {
+		logger.Error("error waiting for background jobs", zap.Error(err))
+	}
+	// handlers that were not drained may still write upload files, they are cleaned on next start then
+	if drained {
+		if err := utils.CleanUploadDirs(); err != nil {
+			logger.Error("error cleaning upload files", zap.Error(err))
+		}
+	}
+	if err := shutdownTracing(closeCtx); err != nil {
+		logger.Error("error flushing traces", zap.Error(err))
+	}
+	logger.Sync()
+
+	if failed {
+		os.Exit(1)
+	}
 }
@@ -1,8 +1,658 @@
 package structs
 
-import globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+import (
+	"time"
+
+	globalStructs "github.com/supperdoggy/spotify-web-project/spotify-globalStructs"
+)
 
 type CreateNewSongReq struct {
 	SongData []byte `json:"song_data"`
+	// Artwork is optional cover image, if empty cover embedded in song tags is used
+	Artwork []byte `json:"artwork"`
+	globalStructs.Song
+}
+
+// UpdateSongReq carries new metadata for song with given ID, empty fields are left untouched
+type UpdateSongReq struct {
 	globalStructs.Song
 }
+
+// PatchSongReq changes only fields that are sent, missing or null fields are left as they are
+// and empty strings clear the field
+type PatchSongReq struct {
+	ID          string     `json:"id"`
+	Name        *string    `json:"name"`
+	Band        *string    `json:"band"`
+	Album       *string    `json:"album"`
+	ReleaseDate *time.Time `json:"release_date"`
+}
+
+type UpdateSongResp struct {
+	Song  globalStructs.Song `json:"song"`
+	Error string             `json:"error"`
+}
+
+type DeleteSongReq struct {
+	ID string `json:"id"`
+}
+
+type DeleteSongResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// DeleteSongDBReq is sent to db, SegmentIDs holds m3u8 and all ts ids of the song
+type DeleteSongDBReq struct {
+	ID         string   `json:"id"`
+	SegmentIDs []string `json:"segment_ids"`
+}
+
+type RemoveSongFromAllPlaylistsReq struct {
+	SongID string `json:"song_id"`
+}
+
+// RemoveSongFromAllPlaylistsResp Playlists are changed playlists as they were before song was removed
+type RemoveSongFromAllPlaylistsResp struct {
+	OK        bool                     `json:"ok"`
+	Playlists []globalStructs.Playlist `json:"playlists"`
+	Error     string                   `json:"error"`
+}
+
+// GetSongsReq describes one page of songs catalog, Cursor is opaque value returned by previous page
+type GetSongsReq struct {
+	Cursor   string `json:"cursor"`
+	Limit    int    `json:"limit"`
+	SortBy   string `json:"sort_by"`
+	Order    string `json:"order"`
+	Band     string `json:"band"`
+	Album    string `json:"album"`
+	YearFrom int    `json:"year_from"`
+	YearTo   int    `json:"year_to"`
+}
+
+type GetSongsResp struct {
+	Songs      []globalStructs.Song `json:"songs"`
+	NextCursor string               `json:"next_cursor"`
+	Error      string               `json:"error"`
+}
+
+type SearchReq struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+type SearchAlbum struct {
+	Name string `json:"name"`
+	Band string `json:"band"`
+}
+
+type SearchResp struct {
+	Songs   []globalStructs.Song `json:"songs"`
+	Artists []string             `json:"artists"`
+	Albums  []SearchAlbum        `json:"albums"`
+	Error   string               `json:"error"`
+}
+
+// Artist is canonical band entity, Aliases are other names songs may use for the same band.
+// Key is normalized Name, artists are looked up by it and by Aliases.
+type Artist struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Key     string   `json:"key"`
+	Aliases []string `json:"aliases"`
+	Artwork string   `json:"artwork"`
+}
+
+type AlbumTrack struct {
+	SongID string `json:"song_id"`
+	Number int    `json:"number"`
+}
+
+type Album struct {
+	ID       string       `json:"id"`
+	ArtistID string       `json:"artist_id"`
+	Name     string       `json:"name"`
+	Artwork  string       `json:"artwork"`
+	Tracks   []AlbumTrack `json:"tracks"`
+}
+
+type NewArtistReq struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	Artwork string   `json:"artwork"`
+}
+
+type NewArtistResp struct {
+	Artist Artist `json:"artist"`
+	Error  string `json:"error"`
+}
+
+// GetArtistReq finds artist by ID or by its name or one of aliases
+type GetArtistReq struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type GetArtistResp struct {
+	Artist Artist `json:"artist"`
+	Error  string `json:"error"`
+}
+
+// GetArtistDBReq finds artist by ID or by Key matching artist key or one of aliases
+type GetArtistDBReq struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type GetAllArtistsResp struct {
+	Artists []Artist `json:"artists"`
+	Error   string   `json:"error"`
+}
+
+type NewAlbumReq struct {
+	ArtistID string       `json:"artist_id"`
+	Name     string       `json:"name"`
+	Artwork  string       `json:"artwork"`
+	Tracks   []AlbumTrack `json:"tracks"`
+}
+
+type NewAlbumResp struct {
+	Album Album  `json:"album"`
+	Error string `json:"error"`
+}
+
+type GetArtistAlbumsReq struct {
+	ArtistID string `json:"artist_id"`
+}
+
+type GetArtistAlbumsResp struct {
+	Artist Artist  `json:"artist"`
+	Albums []Album `json:"albums"`
+	Error  string  `json:"error"`
+}
+
+type GetAlbumTracksReq struct {
+	AlbumID string `json:"album_id"`
+}
+
+// GetAlbumTracksResp holds album and its songs ordered by track number
+type GetAlbumTracksResp struct {
+	Album Album                `json:"album"`
+	Songs []globalStructs.Song `json:"songs"`
+	Error string               `json:"error"`
+}
+
+// UploadArtworkReq attaches cover image to song or album, Kind is "song" or "album"
+type UploadArtworkReq struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Data []byte `json:"data"`
+}
+
+type UploadArtworkResp struct {
+	ArtworkID string         `json:"artwork_id"`
+	URLs      map[int]string `json:"urls"`
+	Error     string         `json:"error"`
+}
+
+// AddArtworkReq stores resized covers in db, every image id is artwork.FileName
+type AddArtworkReq struct {
+	ID     string                   `json:"id"`
+	Images []globalStructs.SongData `json:"images"`
+}
+
+type AddArtworkResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type SetArtworkReq struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	ArtworkID string `json:"artwork_id"`
+}
+
+type SetArtworkResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type GetArtworkReq struct {
+	ID string `json:"id"`
+}
+
+type GetArtworkResp struct {
+	Image globalStructs.SongData `json:"image"`
+	Error string                 `json:"error"`
+}
+
+// UpdatePlaylistReq changes playlist metadata, empty name and image are left untouched.
+// Missing or null Description is left as it is and empty one clears it.
+type UpdatePlaylistReq struct {
+	UserID      string  `json:"user_id"`
+	PlaylistID  string  `json:"playlist_id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Image       []byte  `json:"image"`
+}
+
+// UpdatePlaylistDBReq is UpdatePlaylistReq sent to db with image already stored as artwork
+type UpdatePlaylistDBReq struct {
+	UserID      string  `json:"user_id"`
+	PlaylistID  string  `json:"playlist_id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	ArtworkID   string  `json:"artwork_id"`
+}
+
+type UpdatePlaylistResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// MoveSongInPlaylistReq moves song to Position, positions start from 0
+type MoveSongInPlaylistReq struct {
+	UserID     string `json:"user_id"`
+	PlaylistID string `json:"playlist_id"`
+	SongID     string `json:"song_id"`
+	Position   int    `json:"position"`
+}
+
+type MoveSongInPlaylistResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type AddSongsToPlaylistReq struct {
+	UserID     string   `json:"user_id"`
+	PlaylistID string   `json:"playlist_id"`
+	SongIDs    []string `json:"song_ids"`
+}
+
+type AddSongsToPlaylistResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type RemoveSongsFromPlaylistReq struct {
+	UserID     string   `json:"user_id"`
+	PlaylistID string   `json:"playlist_id"`
+	SongIDs    []string `json:"song_ids"`
+}
+
+type RemoveSongsFromPlaylistResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// PlaylistAccess describes who can see and change playlist, Role and Following are for UserID
+type PlaylistAccess struct {
+	PlaylistID string `json:"playlist_id"`
+	OwnerID    string `json:"owner_id"`
+	Visibility string `json:"visibility"`
+	UserID     string `json:"user_id"`
+	Role       string `json:"role"`
+	Following  bool   `json:"following"`
+}
+
+type GetPlaylistAccessReq struct {
+	PlaylistID string `json:"playlist_id"`
+	UserID     string `json:"user_id"`
+}
+
+type GetPlaylistAccessResp struct {
+	Access PlaylistAccess `json:"access"`
+	Error  string         `json:"error"`
+}
+
+type SetPlaylistVisibilityReq struct {
+	UserID     string `json:"user_id"`
+	PlaylistID string `json:"playlist_id"`
+	Visibility string `json:"visibility"`
+}
+
+type SetPlaylistVisibilityResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// SharePlaylistReq gives TargetUserID Role on playlist, empty Role revokes access
+type SharePlaylistReq struct {
+	UserID       string `json:"user_id"`
+	PlaylistID   string `json:"playlist_id"`
+	TargetUserID string `json:"target_user_id"`
+	Role         string `json:"role"`
+}
+
+type SharePlaylistResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type FollowPlaylistReq struct {
+	UserID     string `json:"user_id"`
+	PlaylistID string `json:"playlist_id"`
+	Follow     bool   `json:"follow"`
+}
+
+type FollowPlaylistResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// PlaylistVersion is playlist state before change made by UserID, so restoring it undoes
+// the change. ChangedSongIDs are songs the change touched, Name and SongIDs are snapshot
+// of playlist. Description and artwork are not versioned, restore keeps current ones.
+// Version is assigned by db.
+type PlaylistVersion struct {
+	PlaylistID     string    `json:"playlist_id"`
+	Version        int       `json:"version"`
+	UserID         string    `json:"user_id"`
+	Action         string    `json:"action"`
+	ChangedSongIDs []string  `json:"changed_song_ids"`
+	Name           string    `json:"name"`
+	SongIDs        []string  `json:"song_ids"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AddPlaylistVersionResp struct {
+	Version int    `json:"version"`
+	Error   string `json:"error"`
+}
+
+type GetPlaylistHistoryReq struct {
+	UserID     string `json:"user_id"`
+	PlaylistID string `json:"playlist_id"`
+}
+
+type GetPlaylistHistoryResp struct {
+	Versions []PlaylistVersion `json:"versions"`
+	Error    string            `json:"error"`
+}
+
+type GetPlaylistVersionReq struct {
+	PlaylistID string `json:"playlist_id"`
+	Version    int    `json:"version"`
+}
+
+type GetPlaylistVersionResp struct {
+	Version PlaylistVersion `json:"version"`
+	Error   string          `json:"error"`
+}
+
+type RestorePlaylistVersionReq struct {
+	UserID     string `json:"user_id"`
+	PlaylistID string `json:"playlist_id"`
+	Version    int    `json:"version"`
+}
+
+type RestorePlaylistVersionResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// SetPlaylistSongsReq replaces playlist name and songs, used to restore versions
+type SetPlaylistSongsReq struct {
+	UserID     string   `json:"user_id"`
+	PlaylistID string   `json:"playlist_id"`
+	Name       string   `json:"name"`
+	SongIDs    []string `json:"song_ids"`
+}
+
+type SetPlaylistSongsResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// ExportPlaylistReq Format is one of m3u, m3u8, xspf or json
+type ExportPlaylistReq struct {
+	UserID     string `json:"user_id"`
+	PlaylistID string `json:"playlist_id"`
+	Format     string `json:"format"`
+}
+
+type PlaylistEntry struct {
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	Location string `json:"location"`
+}
+
+// ImportPlaylistReq creates new playlist from m3u, m3u8, xspf or json file,
+// empty Format is detected from Data and empty Name is taken from file
+type ImportPlaylistReq struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Data   []byte `json:"data"`
+}
+
+type ImportPlaylistResp struct {
+	PlaylistID string          `json:"playlist_id"`
+	Matched    int             `json:"matched"`
+	Unmatched  []PlaylistEntry `json:"unmatched"`
+	Error      string          `json:"error"`
+}
+
+// PlayEvent is one listening event of user, Counted events are plays used in play counts
+type PlayEvent struct {
+	UserID     string    `json:"user_id"`
+	SongID     string    `json:"song_id"`
+	Type       string    `json:"type"`
+	PositionMs int       `json:"position_ms"`
+	Source     string    `json:"source"`
+	Counted    bool      `json:"counted"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PlayEventReq is sent by player, Type is one of start, progress, complete or skip
+type PlayEventReq struct {
+	UserID     string `json:"user_id"`
+	SongID     string `json:"song_id"`
+	Type       string `json:"type"`
+	PositionMs int    `json:"position_ms"`
+}
+
+type PlayEventResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type GetPlayEventsReq struct {
+	Since time.Time `json:"since"`
+}
+
+type GetPlayEventsResp struct {
+	Events []PlayEvent `json:"events"`
+	Error  string      `json:"error"`
+}
+
+type RecentlyPlayedReq struct {
+	UserID string `json:"user_id"`
+	Limit  int    `json:"limit"`
+}
+
+type RecentlyPlayedSong struct {
+	Song     globalStructs.Song `json:"song"`
+	PlayedAt time.Time          `json:"played_at"`
+}
+
+type RecentlyPlayedResp struct {
+	Songs []RecentlyPlayedSong `json:"songs"`
+	Error string               `json:"error"`
+}
+
+// TopReq Range is one of 4_weeks, 6_months or all_time
+type TopReq struct {
+	UserID string `json:"user_id"`
+	Range  string `json:"range"`
+	Limit  int    `json:"limit"`
+}
+
+type SongPlays struct {
+	Song  globalStructs.Song `json:"song"`
+	Plays int                `json:"plays"`
+}
+
+type ArtistPlays struct {
+	Artist string `json:"artist"`
+	Plays  int    `json:"plays"`
+}
+
+type TopTracksResp struct {
+	Songs []SongPlays `json:"songs"`
+	Error string      `json:"error"`
+}
+
+type TopArtistsResp struct {
+	Artists []ArtistPlays `json:"artists"`
+	Error   string        `json:"error"`
+}
+
+// ChartsReq Window is one of day, week or month
+type ChartsReq struct {
+	Window string `json:"window"`
+	Limit  int    `json:"limit"`
+}
+
+type ChartsResp struct {
+	Songs []SongPlays `json:"songs"`
+	Error string      `json:"error"`
+}
+
+// LikeSongReq likes song or removes like when Like is false
+type LikeSongReq struct {
+	UserID string `json:"user_id"`
+	SongID string `json:"song_id"`
+	Like   bool   `json:"like"`
+}
+
+type LikeSongResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// SaveAlbumReq saves album to user library or removes it when Save is false
+type SaveAlbumReq struct {
+	UserID  string `json:"user_id"`
+	AlbumID string `json:"album_id"`
+	Save    bool   `json:"save"`
+}
+
+type SaveAlbumResp struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// LibraryPageReq is one page of user library, Cursor is opaque value returned by previous page
+type LibraryPageReq struct {
+	UserID string `json:"user_id"`
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type LikedSongID struct {
+	SongID  string    `json:"song_id"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+// GetLikedSongIDsResp is db answer, newest likes first
+type GetLikedSongIDsResp struct {
+	Songs      []LikedSongID `json:"songs"`
+	NextCursor string        `json:"next_cursor"`
+	Error      string        `json:"error"`
+}
+
+type LikedSong struct {
+	Song    globalStructs.Song `json:"song"`
+	LikedAt time.Time          `json:"liked_at"`
+}
+
+type GetLikedSongsResp struct {
+	Songs      []LikedSong `json:"songs"`
+	NextCursor string      `json:"next_cursor"`
+	Error      string      `json:"error"`
+}
+
+type GetSavedAlbumsResp struct {
+	Albums     []Album `json:"albums"`
+	NextCursor string  `json:"next_cursor"`
+	Error      string  `json:"error"`
+}
+
+type AreSongsLikedReq struct {
+	UserID  string   `json:"user_id"`
+	SongIDs []string `json:"song_ids"`
+}
+
+// AreSongsLikedResp Liked is in the same order as requested song ids
+type AreSongsLikedResp struct {
+	Liked []bool `json:"liked"`
+	Error string `json:"error"`
+}
+
+// RadioReq needs exactly one seed: SongID, Artist or PlaylistID.
+// UserID is needed to check access to seed playlist.
+type RadioReq struct {
+	UserID     string `json:"user_id"`
+	SongID     string `json:"song_id"`
+	Artist     string `json:"artist"`
+	PlaylistID string `json:"playlist_id"`
+	Limit      int    `json:"limit"`
+}
+
+type RadioResp struct {
+	Songs []globalStructs.Song `json:"songs"`
+	Error string               `json:"error"`
+}
+
+type HealthResp struct {
+	Status string `json:"status"`
+}
+
+// DependencyStatus is result of one readiness check
+type DependencyStatus struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// ReadinessResp Ready is false when any of dependencies is not ok.
+// Breakers are circuit breaker states by downstream host, they do not change Ready.
+type ReadinessResp struct {
+	Ready        bool                        `json:"ready"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+	Breakers     map[string]string           `json:"breakers"`
+}
+
+// ErrorResp is answer of every failed request, Code is one of apperr codes
+type ErrorResp struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+type CreateSongResp struct {
+	Song  globalStructs.Song `json:"song"`
+	Error string             `json:"error"`
+}
+
+type GetSongReq struct {
+	ID string `json:"id"`
+}
+
+type GetSongResp struct {
+	Song  globalStructs.Song `json:"song"`
+	Error string             `json:"error"`
+}
+
+type CreatePlaylistReq struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+// GetPlaylistTracksResp Songs are in playlist order, songs missing in catalog have only id
+type GetPlaylistTracksResp struct {
+	Songs []globalStructs.Song `json:"songs"`
+	Error string               `json:"error"`
+}